@@ -1,67 +1,195 @@
 package main
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"log"
 	"net/http"
+	"os"
+	"os/signal"
+	config "pr-reviewer/configs"
+	"pr-reviewer/internal/domain"
 	"pr-reviewer/internal/http/handlers"
 	"pr-reviewer/internal/repository"
 	"pr-reviewer/internal/services"
+	"syscall"
 	"time"
 )
 
+// сколько ждать завершения текущих HTTP-запросов при остановке
+const shutdownTimeout = 10 * time.Second
+
 type app struct {
 	db   *sql.DB
-	port string
+	conf *config.Conf
 }
 
-func NewApp(db *sql.DB, port string) *app {
-	return &app{db: db, port: port}
+func NewApp(db *sql.DB, conf *config.Conf) *app {
+	return &app{db: db, conf: conf}
 }
 
 func (a *app) Run() {
 
+	// остановка по SIGINT/SIGTERM: сначала HTTP, затем outbox и начатые доставки
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
 	// TEAM
 	teamRepo := repository.NewTeamRepository(a.db)
-	teamService := services.NewTeamService(teamRepo)
-	teamHadnler := handlers.NewTeamHandler(teamService)
 
 	// USER
 	userRepo := repository.NewUserRepository(a.db)
-	userService := services.NewUserService(userRepo)
-	userHandler := handlers.NewUserHandler(userService)
+
+	// OWNERSHIP
+	ownershipRepo := repository.NewOwnershipRepository(a.db)
+	ownershipHandler := handlers.NewOwnershipHandler(services.NewOwnershipService(ownershipRepo, userRepo))
+
+	// EVENTS & WEBHOOKS
+	webhookRepo := repository.NewWebhookRepository(a.db)
+	webhookHandler := handlers.NewWebhookHandler(services.NewWebhookService(webhookRepo))
+	dispatcher := services.NewWebhookDispatcher(webhookRepo, &http.Client{Timeout: a.conf.WebhookTimeout}, a.conf.WebhookMaxAttempts, a.conf.WebhookBackoff)
+	eventRepo := repository.NewEventRepository(a.db)
+
+	// доставки, прерванные прошлой остановкой, продолжаются до запуска outbox
+	if err := dispatcher.Resume(); err != nil {
+		log.Println("resume webhook deliveries:", err)
+	}
+
+	// события пишутся в outbox вместе с изменениями и рассылаются в фоне
+	outbox := services.NewOutboxDispatcher(repository.NewOutboxRepository(a.db), dispatcher, a.conf.OutboxInterval, a.conf.OutboxBatchSize, a.conf.OutboxBackoff)
+	outboxDone := make(chan struct{})
+	go func() {
+		defer close(outboxDone)
+		outbox.Run(ctx)
+	}()
+
+	// запрос ревью в code host, только если настроен клиент
+	codeHostRepo := repository.NewCodeHostRepository(a.db)
+	requester := a.reviewRequester(codeHostRepo)
 
 	// PULL REQUEST
 	pullRequestRepo := repository.NewPullRequestRepository(a.db)
-	pullRequestService := services.NewPullRequestService(pullRequestRepo, userRepo)
-	pullRequestHandler := handlers.NewPullRequestHandler(pullRequestService)
+	selectors := services.NewReviewerSelectors(pullRequestRepo, a.conf.ReviewerStrategy, a.conf.TeamReviewerStrategy)
+	pullRequestService := services.NewPullRequestService(pullRequestRepo, userRepo, teamRepo, ownershipRepo, eventRepo, selectors, requester)
+	pullRequestHandler := handlers.NewPullRequestHandler(pullRequestService, a.conf.AdminToken)
+
+	userService := services.NewUserService(userRepo, pullRequestService)
+	teamHadnler := handlers.NewTeamHandler(services.NewTeamService(teamRepo, userRepo, pullRequestService))
+	userHandler := handlers.NewUserHandler(userService)
+
+	// CODE HOST INTEGRATIONS
+	integrationService := services.NewIntegrationService(pullRequestService, repository.NewIdentityRepository(a.db), userRepo, codeHostRepo)
+	integrationHandler := handlers.NewIntegrationHandler(integrationService, a.conf.GitHubWebhookSecret, a.conf.GitLabWebhookToken)
 
 	// STATS
 	statsHandler := handlers.NewStatsHandler(services.NewStatsService(pullRequestRepo))
 
-	http.HandleFunc("/stats/reviewers", statsHandler.GetReviewersStats)
-
 	mux := http.NewServeMux()
 
+	mux.HandleFunc("/stats/reviewers", statsHandler.GetReviewersStats)
+
+	mux.HandleFunc("/users/get", userHandler.Get)
+	mux.HandleFunc("/users/list", userHandler.List)
+	mux.HandleFunc("/users/update", userHandler.Update)
 	mux.HandleFunc("/users/setIsActive", userHandler.SetIsActive)
+	mux.HandleFunc("/users/setMaxOpenReviews", userHandler.SetMaxOpenReviews)
 	mux.HandleFunc("/users/getReview", pullRequestHandler.GetReview)
+	mux.HandleFunc("/users/unavailability/add", userHandler.AddUnavailability)
+	mux.HandleFunc("/users/unavailability/list", userHandler.ListUnavailability)
+	mux.HandleFunc("/users/unavailability/delete", userHandler.DeleteUnavailability)
 
 	mux.HandleFunc("/team/add", teamHadnler.CreateTeam)
 	mux.HandleFunc("/team/get", teamHadnler.GetTeam)
+	mux.HandleFunc("/team/settings/get", teamHadnler.GetSettings)
+	mux.HandleFunc("/team/settings/update", teamHadnler.UpdateSettings)
+	mux.HandleFunc("/team/members/add", teamHadnler.AddMember)
+	mux.HandleFunc("/team/members/remove", teamHadnler.RemoveMember)
+	mux.HandleFunc("/team/rename", teamHadnler.Rename)
+	mux.HandleFunc("/team/delete", teamHadnler.Delete)
+	mux.HandleFunc("/team/setParent", teamHadnler.SetParent)
+
+	mux.HandleFunc("/owners/add", ownershipHandler.CreateRule)
+	mux.HandleFunc("/owners/list", ownershipHandler.ListRules)
+	mux.HandleFunc("/owners/delete", ownershipHandler.DeleteRule)
+
+	mux.HandleFunc("/webhooks/add", webhookHandler.Create)
+	mux.HandleFunc("/webhooks/list", webhookHandler.List)
+	mux.HandleFunc("/webhooks/delete", webhookHandler.Delete)
+	mux.HandleFunc("/webhooks/deliveries", webhookHandler.Deliveries)
 
 	mux.HandleFunc("/pullRequest/create", pullRequestHandler.CreatePR)
 	mux.HandleFunc("/pullRequest/merge", pullRequestHandler.Merge)
 	mux.HandleFunc("/pullRequest/reassign", pullRequestHandler.Reassign)
+	mux.HandleFunc("/pullRequest/reviewers/add", pullRequestHandler.AddReviewer)
+	mux.HandleFunc("/pullRequest/reviewers/remove", pullRequestHandler.RemoveReviewer)
+	mux.HandleFunc("/pullRequest/review", pullRequestHandler.Review)
+	mux.HandleFunc("/pullRequest/close", pullRequestHandler.Close)
+	mux.HandleFunc("/pullRequest/reopen", pullRequestHandler.Reopen)
+	mux.HandleFunc("/pullRequest/ready", pullRequestHandler.Ready)
+	mux.HandleFunc("/pullRequest/history", pullRequestHandler.History)
+	mux.HandleFunc("/pullRequest/get", pullRequestHandler.Get)
+	mux.HandleFunc("/pullRequest/list", pullRequestHandler.List)
+
+	mux.HandleFunc("/integrations/github/webhook", integrationHandler.GitHubWebhook)
+	mux.HandleFunc("/integrations/gitlab/webhook", integrationHandler.GitLabWebhook)
+	mux.HandleFunc("/integrations/identities/add", integrationHandler.AddIdentity)
+	mux.HandleFunc("/integrations/identities/list", integrationHandler.ListIdentities)
+	mux.HandleFunc("/integrations/identities/delete", integrationHandler.DeleteIdentity)
 
 	server := &http.Server{
-		Addr:              a.port,
+		Addr:              a.conf.ApiPort,
 		Handler:           mux,
 		ReadHeaderTimeout: 5 * time.Second,
 	}
 
-	log.Println("listen and serve on:", a.port)
-	if err := server.ListenAndServe(); err != nil {
-		log.Fatal("listen and serve: ", err)
+	go func() {
+		log.Println("listen and serve on:", a.conf.ApiPort)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Println("listen and serve:", err)
+			stop()
+		}
+	}()
+
+	<-ctx.Done()
+	log.Println("shutting down")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		log.Println("server shutdown:", err)
+	}
+
+	<-outboxDone
+	dispatcher.Shutdown()
+	if r, ok := requester.(*services.CodeHostReviewRequester); ok {
+		r.Shutdown()
+	}
+
+}
+
+// reviewRequester — nil, если CODE_HOST_CLIENT не задан или не настроен
+func (a *app) reviewRequester(links repository.CodeHostRepository) services.ReviewRequester {
+	var clients map[string]services.CodeHostClient
+
+	switch a.conf.CodeHostClient {
+	case "":
+		return nil
+	case "github":
+		if a.conf.GitHubToken == "" {
+			log.Println("CODE_HOST_CLIENT=github without GITHUB_TOKEN, review requests disabled")
+			return nil
+		}
+		clients = map[string]services.CodeHostClient{
+			domain.ProviderGitHub: services.NewGitHubClient(a.conf.GitHubAPIURL, a.conf.GitHubToken, &http.Client{}),
+		}
+	case "fake":
+		fake := &services.FakeCodeHostClient{}
+		clients = map[string]services.CodeHostClient{domain.ProviderGitHub: fake, domain.ProviderGitLab: fake}
+	default:
+		log.Printf("unknown CODE_HOST_CLIENT=%q, review requests disabled", a.conf.CodeHostClient)
+		return nil
 	}
 
+	return services.NewCodeHostReviewRequester(links, clients, a.conf.CodeHostMaxAttempts, a.conf.CodeHostBackoff, a.conf.CodeHostTimeout)
 }
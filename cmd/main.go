@@ -27,7 +27,7 @@ func main() {
 		log.Fatal("db connection: ", err)
 	}
 
-	app := NewApp(db, conf.ApiPort)
+	app := NewApp(db, conf)
 	app.Run()
 
 }
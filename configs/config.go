@@ -2,12 +2,40 @@ package config
 
 import (
 	"fmt"
+	"log"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 )
 
 type Conf struct {
-	DbConn  string
-	ApiPort string
+	DbConn     string
+	ApiPort    string
+	AdminToken string // X-Admin-Token для административных операций
+
+	ReviewerStrategy     string
+	TeamReviewerStrategy map[string]string // team_name -> strategy
+
+	WebhookMaxAttempts int
+	WebhookBackoff     time.Duration // задержка перед повтором, удваивается с каждой попыткой
+	WebhookTimeout     time.Duration
+
+	OutboxInterval  time.Duration // как часто разбирать outbox
+	OutboxBatchSize int
+	OutboxBackoff   time.Duration // задержка повтора события, удваивается с каждой попыткой
+
+	// входящие вебхуки code host; пусто — интеграция выключена
+	GitHubWebhookSecret string
+	GitLabWebhookToken  string
+
+	// запрос ревью в code host после назначения: "" (выключено), github, fake
+	CodeHostClient      string
+	GitHubToken         string
+	GitHubAPIURL        string
+	CodeHostMaxAttempts int
+	CodeHostBackoff     time.Duration
+	CodeHostTimeout     time.Duration
 }
 
 func Load() *Conf {
@@ -22,11 +50,90 @@ func Load() *Conf {
 		apiport = ":8080"
 	}
 
+	strategy := os.Getenv("REVIEWER_STRATEGY")
+	if strategy == "" {
+		strategy = "random"
+	}
+
 	conn := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable", dbhost, dbport, dbuser, dbpass, dbname)
 
 	return &Conf{
-		DbConn:  conn,
-		ApiPort: apiport,
+		DbConn:     conn,
+		ApiPort:    apiport,
+		AdminToken: os.Getenv("ADMIN_TOKEN"),
+
+		ReviewerStrategy:     strategy,
+		TeamReviewerStrategy: parseTeamStrategies(os.Getenv("REVIEWER_STRATEGY_TEAMS")),
+
+		WebhookMaxAttempts: envInt("WEBHOOK_MAX_ATTEMPTS", 5),
+		WebhookBackoff:     envDuration("WEBHOOK_BACKOFF", time.Second),
+		WebhookTimeout:     envDuration("WEBHOOK_TIMEOUT", 5*time.Second),
+
+		OutboxInterval:  envDuration("OUTBOX_INTERVAL", time.Second),
+		OutboxBatchSize: envInt("OUTBOX_BATCH_SIZE", 100),
+		OutboxBackoff:   envDuration("OUTBOX_BACKOFF", 5*time.Second),
+
+		GitHubWebhookSecret: os.Getenv("GITHUB_WEBHOOK_SECRET"),
+		GitLabWebhookToken:  os.Getenv("GITLAB_WEBHOOK_TOKEN"),
+
+		CodeHostClient:      os.Getenv("CODE_HOST_CLIENT"),
+		GitHubToken:         os.Getenv("GITHUB_TOKEN"),
+		GitHubAPIURL:        envString("GITHUB_API_URL", "https://api.github.com"),
+		CodeHostMaxAttempts: envInt("CODE_HOST_MAX_ATTEMPTS", 5),
+		CodeHostBackoff:     envDuration("CODE_HOST_BACKOFF", 2*time.Second),
+		CodeHostTimeout:     envDuration("CODE_HOST_TIMEOUT", 10*time.Second),
+	}
+
+}
+
+// формат: "backend=round_robin,docs=least_loaded"
+func parseTeamStrategies(raw string) map[string]string {
+	res := make(map[string]string)
+
+	for _, pair := range strings.Split(raw, ",") {
+		team, strategy, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok || team == "" || strategy == "" {
+			continue
+		}
+		res[strings.TrimSpace(team)] = strings.TrimSpace(strategy)
+	}
+
+	return res
+}
+
+func envString(key, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
 	}
+	return def
+}
 
+func envInt(key string, def int) int {
+	raw := os.Getenv(key)
+	if raw == "" {
+		return def
+	}
+
+	v, err := strconv.Atoi(raw)
+	if err != nil {
+		log.Printf("invalid %s=%q, using %d", key, raw, def)
+		return def
+	}
+	return v
+}
+
+// формат time.ParseDuration: "500ms", "2s"; нулевые и отрицательные значения
+// не допускаются (time.NewTicker с ними паникует)
+func envDuration(key string, def time.Duration) time.Duration {
+	raw := os.Getenv(key)
+	if raw == "" {
+		return def
+	}
+
+	v, err := time.ParseDuration(raw)
+	if err != nil || v <= 0 {
+		log.Printf("invalid %s=%q, using %s", key, raw, def)
+		return def
+	}
+	return v
 }
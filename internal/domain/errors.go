@@ -1,6 +1,10 @@
 package domain
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
 
 var (
 	ErrNotFound       = errors.New("NOT_FOUND")
@@ -11,8 +15,58 @@ var (
 	ErrPRMerged    = errors.New("PR_MERGED")
 	ErrNotAssigned = errors.New("NOT_ASSIGNED")
 	ErrNoCandidate = errors.New("NO_CANDIDATE")
+
+	ErrInvalidSettings    = errors.New("INVALID_SETTINGS")
+	ErrNotEnoughReviewers = errors.New("NOT_ENOUGH_REVIEWERS")
+	ErrInvalidVerdict     = errors.New("INVALID_VERDICT")
+	ErrMergeBlocked       = errors.New("MERGE_BLOCKED")
+	ErrPRClosed           = errors.New("PR_CLOSED")
+	ErrPRDraft            = errors.New("PR_DRAFT")
+	ErrInvalidPeriod      = errors.New("INVALID_PERIOD")
+	ErrNoCapacity         = errors.New("NO_CAPACITY")
+	ErrInvalidCapacity    = errors.New("INVALID_CAPACITY")
+	ErrInvalidRule        = errors.New("INVALID_RULE")
+	ErrSelfReview         = errors.New("SELF_REVIEW")
+	ErrUserInactive       = errors.New("USER_INACTIVE")
+	ErrUserUnavailable    = errors.New("USER_UNAVAILABLE")
+	ErrAlreadyAssigned    = errors.New("ALREADY_ASSIGNED")
+	ErrInvalidWebhook     = errors.New("INVALID_WEBHOOK")
+	ErrInvalidSignature   = errors.New("INVALID_SIGNATURE")
+	ErrInvalidPayload     = errors.New("INVALID_PAYLOAD")
+	ErrUnknownIdentity    = errors.New("UNKNOWN_IDENTITY")
+	ErrInvalidFilter      = errors.New("INVALID_FILTER")
+	ErrInvalidCursor      = errors.New("INVALID_CURSOR")
+	ErrTeamNotEmpty       = errors.New("TEAM_NOT_EMPTY")
+	ErrInvalidParent      = errors.New("INVALID_PARENT")
+	ErrInvalidRole        = errors.New("INVALID_ROLE")
+	ErrLeadRequired       = errors.New("LEAD_REQUIRED")
+	ErrConcurrentUpdate   = errors.New("CONCURRENT_UPDATE")
+	ErrInvalidUsername    = errors.New("INVALID_USERNAME")
 )
 
+// MergeBlockedError — почему merge запрещён; errors.Is(err, ErrMergeBlocked) == true
+type MergeBlockedError struct {
+	Required         int
+	Approved         int
+	Pending          []string // ревьюверы без APPROVED
+	ChangesRequested []string
+}
+
+func (e *MergeBlockedError) Error() string {
+	msg := fmt.Sprintf("approvals %d/%d", e.Approved, e.Required)
+	if e.Approved < e.Required && len(e.Pending) > 0 {
+		msg += ", missing approval from: " + strings.Join(e.Pending, ", ")
+	}
+	if len(e.ChangesRequested) > 0 {
+		msg += ", changes requested by: " + strings.Join(e.ChangesRequested, ", ")
+	}
+	return msg
+}
+
+func (e *MergeBlockedError) Unwrap() error {
+	return ErrMergeBlocked
+}
+
 type ApiError struct {
 	Error struct {
 		Code    string `json:"code"`
@@ -1,48 +1,417 @@
 package domain
 
+import (
+	"encoding/json"
+	"slices"
+	"time"
+)
+
 type Team struct {
-	ID       int64  `json:"id"`
-	TeamName string `json:"team_name"`
-	Members  []User `json:"members"`
+	ID         int64         `json:"id"`
+	TeamName   string        `json:"team_name"`
+	ParentID   int64         `json:"-"`
+	ParentTeam string        `json:"parent_team,omitempty"` // пусто — команда верхнего уровня
+	Members    []User        `json:"members"`
+	Settings   *TeamSettings `json:"settings,omitempty"`
+	Subteams   []Team        `json:"subteams,omitempty"` // только в /team/get?include_subteams=true
+}
+
+type TeamSettings struct {
+	ReviewersCount int    `json:"reviewers_count"`
+	MinReviewers   int    `json:"min_reviewers"`
+	Strategy       string `json:"strategy,omitempty"` // пусто — стратегия из конфигурации
+
+	// сколько APPROVED нужно для merge; если ревьюверов меньше, merge только с force
+	RequiredApprovals int `json:"required_approvals"`
+
+	// команды, из которых добираются ревьюверы, если в своей не хватает активных
+	FallbackTeams []string `json:"fallback_teams"`
+
+	// где ещё искать замену при reassign: none (пусто), siblings, parent, siblings_parent
+	Escalation string `json:"escalation,omitempty"`
+
+	// обязательный лид среди ревьюверов: none (пусто), always, title — если название PR
+	// совпадает с регулярным выражением LeadTitlePattern
+	LeadRule         string `json:"lead_rule,omitempty"`
+	LeadTitlePattern string `json:"lead_title_pattern,omitempty"`
+}
+
+// TeamSettingsUpdate — частичное обновление, nil поля не меняются
+type TeamSettingsUpdate struct {
+	ReviewersCount    *int      `json:"reviewers_count"`
+	MinReviewers      *int      `json:"min_reviewers"`
+	RequiredApprovals *int      `json:"required_approvals"`
+	Strategy          *string   `json:"strategy"`
+	FallbackTeams     *[]string `json:"fallback_teams"`
+	Escalation        *string   `json:"escalation"`
+	LeadRule          *string   `json:"lead_rule"`
+	LeadTitlePattern  *string   `json:"lead_title_pattern"`
 }
 
 type User struct {
 	ID       string `json:"user_id"`
 	UserName string `json:"username"`
 	IsActive bool   `json:"is_active"`
-	TeamID   int64  `json:"team_id"`
+	TeamID   int64  `json:"team_id"` // основная команда; 0 — пользователь исключён из всех команд
+
+	MaxOpenReviews *int `json:"max_open_reviews,omitempty"` // nil — без лимита
+
+	TeamIDs []int64 `json:"-"` // все команды пользователя, включая основную
+
+	Role string `json:"role,omitempty"` // роль в команде (только в составе Team): member или lead
+}
+
+// роли участника команды
+const (
+	RoleMember = "member"
+	RoleLead   = "lead"
+)
+
+// IsValidRole: пустая роль допустима — member для нового участника, прежняя для существующего
+func IsValidRole(role string) bool {
+	return role == "" || role == RoleMember || role == RoleLead
+}
+
+func (u *User) InTeam(teamID int64) bool {
+	return teamID != 0 && slices.Contains(u.TeamIDs, teamID)
+}
+
+// Unavailability — период, когда пользователь не назначается ревьювером
+type Unavailability struct {
+	ID       int64     `json:"id"`
+	UserID   string    `json:"user_id"`
+	StartsAt time.Time `json:"starts_at"`
+	EndsAt   time.Time `json:"ends_at"`
+	Reason   string    `json:"reason"`
 }
 
 type PullRequest struct {
-	ID                string   `json:"pull_request_id"`
-	Name              string   `json:"pull_request_name"`
-	AuthorID          string   `json:"author_id"`
-	Status            string   `json:"status"`
-	AssignedReviewers []string `json:"assigned_reviewers"`
-	CreatedAt         string   `json:"createdAt,omitempty"`
-	MergedAt          *string  `json:"mergedAt,omitempty"`
+	ID                string     `json:"pull_request_id"`
+	Name              string     `json:"pull_request_name"`
+	AuthorID          string     `json:"author_id"`
+	TeamID            int64      `json:"-"`
+	TeamName          string     `json:"team_name,omitempty"` // команда, из которой назначаются ревьюверы
+	Status            string     `json:"status"`
+	AssignedReviewers []Reviewer `json:"assigned_reviewers"`
+	ChangedFiles      []string   `json:"changed_files,omitempty"`
+	CreatedAt         string     `json:"createdAt,omitempty"`
+	MergedAt          *string    `json:"mergedAt,omitempty"`
+	ClosedAt          *string    `json:"closedAt,omitempty"`
+	ForceMerged       bool       `json:"force_merged,omitempty"` // merge в обход проверки approve
+	ClosedFrom        string     `json:"-"`                      // DRAFT или OPEN для CLOSED; пусто — закрыт до учёта
+
+	// предупреждения операции (не сохраняются), например NO_CAPACITY
+	Warnings []Warning `json:"warnings,omitempty"`
+}
+
+type Warning struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func (pr *PullRequest) ReviewerIDs() []string {
+	ids := make([]string, 0, len(pr.AssignedReviewers))
+	for _, r := range pr.AssignedReviewers {
+		ids = append(ids, r.UserID)
+	}
+	return ids
+}
+
+// Reviewer — назначенный ревьювер и его последний вердикт
+type Reviewer struct {
+	UserID    string  `json:"user_id"`
+	Fallback  bool    `json:"fallback"`             // назначен из резервной команды
+	OwnerRule string  `json:"owner_rule,omitempty"` // шаблон правила владения, по которому выбран
+	Verdict   string  `json:"verdict,omitempty"`
+	VerdictAt *string `json:"verdict_at,omitempty"`
+}
+
+// OwnershipRule — CODEOWNERS-подобное правило: шаблон пути -> пользователь или команда
+type OwnershipRule struct {
+	ID       int64  `json:"rule_id"`
+	Pattern  string `json:"pattern"`
+	UserID   string `json:"user_id,omitempty"`
+	TeamName string `json:"team_name,omitempty"`
+	TeamID   int64  `json:"-"`
+}
+
+type Review struct {
+	ID            int64  `json:"review_id"`
+	PullRequestID string `json:"pull_request_id"`
+	ReviewerID    string `json:"reviewer_id"`
+	Verdict       string `json:"verdict"`
+	Comment       string `json:"comment,omitempty"`
+	CreatedAt     string `json:"created_at"`
+}
+
+// Event — запись журнала: кто (actor) и что сделал с PR или пользователем
+type Event struct {
+	ID            int64           `json:"event_id"`
+	PullRequestID string          `json:"pull_request_id,omitempty"`
+	UserID        string          `json:"user_id,omitempty"` // пользователь, над которым выполнено действие
+	Actor         string          `json:"actor,omitempty"`
+	Type          string          `json:"type"`
+	Payload       json.RawMessage `json:"payload,omitempty"`
+	CreatedAt     string          `json:"created_at"`
+}
+
+const (
+	EventPRCreated          = "PR_CREATED"
+	EventReviewersAssigned  = "REVIEWERS_ASSIGNED"
+	EventReviewerReassigned = "REVIEWER_REASSIGNED"
+	EventReviewerRemoved    = "REVIEWER_REMOVED"
+	EventReviewSubmitted    = "REVIEW_SUBMITTED"
+	EventPRMerged           = "PR_MERGED"
+	EventPRClosed           = "PR_CLOSED"
+	EventPRReopened         = "PR_REOPENED"
+	EventPRReady            = "PR_READY"
+	EventUserActivated      = "USER_ACTIVATED"
+	EventUserDeactivated    = "USER_DEACTIVATED"
+	EventUserTeamChanged    = "USER_TEAM_CHANGED"
+)
+
+func IsKnownEventType(t string) bool {
+	switch t {
+	case EventPRCreated, EventReviewersAssigned, EventReviewerReassigned, EventReviewerRemoved,
+		EventReviewSubmitted, EventPRMerged, EventPRClosed, EventPRReopened, EventPRReady,
+		EventUserActivated, EventUserDeactivated, EventUserTeamChanged:
+		return true
+	}
+	return false
+}
+
+// OutboxMessage — событие, ожидающее рассылки
+type OutboxMessage struct {
+	ID        int64
+	Event     Event
+	Attempts  int
+	LastError string
+}
+
+// Identity — связь логина на code host с пользователем сервиса
+type Identity struct {
+	Provider string `json:"provider"`
+	Login    string `json:"login"`
+	UserID   string `json:"user_id"`
+}
+
+const (
+	ProviderGitHub = "github"
+	ProviderGitLab = "gitlab"
+)
+
+func IsKnownProvider(p string) bool {
+	return p == ProviderGitHub || p == ProviderGitLab
+}
+
+// CodeHostEvent — событие PR от GitHub/GitLab, приведённое к общему виду
+type CodeHostEvent struct {
+	Provider      string
+	Action        string
+	PullRequestID string
+	Title         string
+	AuthorLogin   string
+	SenderLogin   string
+	Draft         bool
+
+	Repository string // owner/repo на GitHub, path_with_namespace на GitLab
+	Number     int64  // номер PR (iid MR) внутри репозитория
 }
 
+// CodeHostPullRequest — PR на code host, из которого создан PR сервиса
+type CodeHostPullRequest struct {
+	PullRequestID string
+	Provider      string
+	Repository    string
+	Number        int64
+}
+
+const (
+	CodeHostOpened   = "opened"
+	CodeHostMerged   = "merged"
+	CodeHostClosed   = "closed"
+	CodeHostReopened = "reopened"
+	CodeHostReady    = "ready"
+)
+
+// Webhook — подписка на события журнала
+type Webhook struct {
+	ID        int64    `json:"webhook_id"`
+	URL       string   `json:"url"`
+	Events    []string `json:"events"`           // пусто — все события
+	Secret    string   `json:"secret,omitempty"` // ключ HMAC, в ответах не возвращается
+	CreatedAt string   `json:"created_at,omitempty"`
+}
+
+func (w *Webhook) Accepts(eventType string) bool {
+	return len(w.Events) == 0 || slices.Contains(w.Events, eventType)
+}
+
+// WebhookDelivery — попытки доставки одного события одному подписчику
+type WebhookDelivery struct {
+	ID           int64  `json:"delivery_id"`
+	WebhookID    int64  `json:"webhook_id"`
+	EventID      int64  `json:"event_id"`
+	EventType    string `json:"event_type"`
+	Status       string `json:"status"`
+	Attempts     int    `json:"attempts"`
+	ResponseCode *int   `json:"response_code,omitempty"`
+	LastError    string `json:"last_error,omitempty"`
+	CreatedAt    string `json:"created_at"`
+	UpdatedAt    string `json:"updated_at"`
+}
+
+// PendingDelivery — незавершённая доставка с подпиской и событием, для возобновления после рестарта
+type PendingDelivery struct {
+	Delivery WebhookDelivery
+	Webhook  Webhook
+	Event    Event
+}
+
+const (
+	DeliveryPending = "PENDING"
+	DeliverySuccess = "SUCCESS"
+	DeliveryFailed  = "FAILED"
+)
+
 type UserResponse struct {
 	UserID   string `json:"user_id"`
 	UserName string `json:"username"`
 	TeamName string `json:"team_name"`
 	IsActive bool   `json:"is_active"`
+
+	MaxOpenReviews *int `json:"max_open_reviews,omitempty"`
+}
+
+// UserFilter — параметры /users/list; пустые поля не фильтруют
+type UserFilter struct {
+	TeamName   string
+	IsActive   *bool
+	NamePrefix string // начало username без учёта регистра
+	Limit      int
+	Cursor     string
+}
+
+type UserPage struct {
+	Users      []UserResponse `json:"users"`
+	NextCursor *string        `json:"next_cursor"`
+}
+
+// ReassignReport — результат переназначения открытых ревью пользователя
+type ReassignReport struct {
+	Reassigned  []ReassignedReview `json:"reassigned"`
+	NoCandidate []SkippedReview    `json:"no_candidate"` // PR, где замену найти не удалось, с причиной
+
+	// PR, до которых не дошли из-за ошибки (включая PR, где она произошла); ревьювер в них остаётся
+	Pending []string `json:"pending,omitempty"`
+}
+
+type ReassignedReview struct {
+	PullRequestID string `json:"pull_request_id"`
+	ReplacedBy    string `json:"replaced_by"`
+}
+
+// SkippedReview — ревью без замены; Reason: NO_CANDIDATE, NO_CAPACITY или LEAD_REQUIRED
+type SkippedReview struct {
+	PullRequestID string `json:"pull_request_id"`
+	Reason        string `json:"reason"`
 }
 
 type PullRequestShort struct {
-	ID       string `json:"pull_request_id"`
-	Name     string `json:"pull_request_name"`
-	AuthorID string `json:"author_id"`
-	Status   string `json:"status"`
+	ID        string `json:"pull_request_id"`
+	Name      string `json:"pull_request_name"`
+	AuthorID  string `json:"author_id"`
+	Status    string `json:"status"`
+	CreatedAt string `json:"createdAt,omitempty"`
+}
+
+// ReviewFilter — выборка PR, где пользователь назначен ревьювером
+type ReviewFilter struct {
+	UserID        string
+	Statuses      []string   // пусто — любые
+	CreatedAfter  *time.Time // включительно
+	CreatedBefore *time.Time // не включительно
+	Sort          string
+	Limit         int
+	Cursor        string // next_cursor предыдущей страницы
+}
+
+const (
+	SortCreatedDesc = "-created_at"
+	SortCreatedAsc  = "created_at"
+
+	DefaultPageLimit = 50
+	MaxPageLimit     = 100
+)
+
+// PageCursor — последняя строка предыдущей страницы (created_at, pull_request_id)
+type PageCursor struct {
+	CreatedAt time.Time `json:"created_at"`
+	ID        string    `json:"id"`
+	Sort      string    `json:"sort"` // сортировка, для которой выдан курсор
+}
+
+// PullRequestFilter — параметры /pullRequest/list; пустые поля не фильтруют
+type PullRequestFilter struct {
+	AuthorID      string
+	TeamName      string // целевая команда PR
+	ReviewerID    string
+	Statuses      []string
+	Title         string // подстрока без учёта регистра
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
+	MergedAfter   *time.Time
+	MergedBefore  *time.Time
+	Sort          string
+	Limit         int
+	Cursor        string
+}
+
+// PullRequestSummary — строка списка PR вместе с ревьюверами
+type PullRequestSummary struct {
+	PullRequestShort
+	MergedAt          *string    `json:"mergedAt,omitempty"`
+	AssignedReviewers []Reviewer `json:"assigned_reviewers"`
+}
+
+type PullRequestListPage struct {
+	PullRequests []PullRequestSummary `json:"pull_requests"`
+	NextCursor   *string              `json:"next_cursor"`
+}
+
+type PullRequestPage struct {
+	PullRequests []PullRequestShort `json:"pull_requests"`
+	NextCursor   *string            `json:"next_cursor"` // nil — страниц больше нет
 }
 
 type ReviewerStat struct {
-	UserID string `json:"user_id"`
-	Count  int    `json:"count"`
+	UserID    string `json:"user_id"`
+	Count     int    `json:"count"`
+	OpenCount int    `json:"open_count"`
 }
 
 const (
+	StatusDraft  = "DRAFT"
 	StatusOpen   = "OPEN"
 	StatusMerged = "MERGED"
+	StatusClosed = "CLOSED"
 )
+
+const (
+	VerdictApproved         = "APPROVED"
+	VerdictChangesRequested = "CHANGES_REQUESTED"
+	VerdictCommented        = "COMMENTED"
+)
+
+func IsValidVerdict(v string) bool {
+	return v == VerdictApproved || v == VerdictChangesRequested || v == VerdictCommented
+}
+
+const (
+	DefaultReviewersCount    = 2
+	DefaultRequiredApprovals = 1
+)
+
+func DefaultTeamSettings() *TeamSettings {
+	return &TeamSettings{ReviewersCount: DefaultReviewersCount, RequiredApprovals: DefaultRequiredApprovals}
+}
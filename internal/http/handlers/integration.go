@@ -0,0 +1,241 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"pr-reviewer/internal/domain"
+	"pr-reviewer/internal/services"
+	"pr-reviewer/internal/utils"
+)
+
+// предел размера тела вебхука code host
+const maxWebhookBody = 1 << 20
+
+type IntegrationHandler struct {
+	Service      services.IntegrationService
+	GitHubSecret string // пусто — интеграция выключена
+	GitLabToken  string
+}
+
+func NewIntegrationHandler(s services.IntegrationService, githubSecret, gitlabToken string) *IntegrationHandler {
+	return &IntegrationHandler{Service: s, GitHubSecret: githubSecret, GitLabToken: gitlabToken}
+}
+
+// GitHubWebhook handles POST /integrations/github/webhook
+func (h *IntegrationHandler) GitHubWebhook(w http.ResponseWriter, r *http.Request) {
+	body, ok := h.readWebhook(w, r, h.GitHubSecret != "")
+	if !ok {
+		return
+	}
+
+	if !services.VerifyGitHubSignature(h.GitHubSecret, body, r.Header.Get("X-Hub-Signature-256")) {
+		w.WriteHeader(http.StatusUnauthorized)
+		utils.WriteJSON(w, domain.ErrorResponse("INVALID_SIGNATURE", "X-Hub-Signature-256 does not match"))
+		return
+	}
+
+	event, err := services.ParseGitHubEvent(r.Header.Get("X-GitHub-Event"), body)
+	h.apply(w, event, err)
+}
+
+// GitLabWebhook handles POST /integrations/gitlab/webhook
+func (h *IntegrationHandler) GitLabWebhook(w http.ResponseWriter, r *http.Request) {
+	body, ok := h.readWebhook(w, r, h.GitLabToken != "")
+	if !ok {
+		return
+	}
+
+	if !services.VerifyGitLabToken(h.GitLabToken, r.Header.Get("X-Gitlab-Token")) {
+		w.WriteHeader(http.StatusUnauthorized)
+		utils.WriteJSON(w, domain.ErrorResponse("INVALID_SIGNATURE", "X-Gitlab-Token does not match"))
+		return
+	}
+
+	event, err := services.ParseGitLabEvent(r.Header.Get("X-Gitlab-Event"), body)
+	h.apply(w, event, err)
+}
+
+func (h *IntegrationHandler) readWebhook(w http.ResponseWriter, r *http.Request, enabled bool) ([]byte, bool) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		utils.WriteJSON(w, domain.ErrorResponse("METHOD_NOT_ALLOWED", "method not allowed"))
+		return nil, false
+	}
+
+	if !enabled {
+		w.WriteHeader(http.StatusForbidden)
+		utils.WriteJSON(w, domain.ErrorResponse("FORBIDDEN", "integration is not configured"))
+		return nil, false
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxWebhookBody))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		utils.WriteJSON(w, domain.ErrorResponse("INVALID_JSON", "failed to read body"))
+		return nil, false
+	}
+
+	return body, true
+}
+
+// общий ответ на событие code host; код ответа виден в журнале доставок GitHub/GitLab
+func (h *IntegrationHandler) apply(w http.ResponseWriter, event *domain.CodeHostEvent, err error) {
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		utils.WriteJSON(w, domain.ErrorResponse("INVALID_PAYLOAD", "unsupported or malformed pull request payload"))
+		return
+	}
+
+	if event == nil {
+		w.WriteHeader(http.StatusOK)
+		utils.WriteJSON(w, map[string]any{
+			"ignored": true,
+		})
+		return
+	}
+
+	pr, err := h.Service.Apply(event)
+	if err != nil {
+
+		switch {
+		case errors.Is(err, domain.ErrPRExists):
+			// повторная доставка opened
+			w.WriteHeader(http.StatusOK)
+			utils.WriteJSON(w, map[string]any{
+				"ignored": true,
+				"reason":  "PR_EXISTS",
+			})
+		case errors.Is(err, domain.ErrUnknownIdentity):
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			utils.WriteJSON(w, domain.ErrorResponse("UNKNOWN_IDENTITY", event.Provider+" login "+event.AuthorLogin+" is not mapped to a user"))
+		case errors.Is(err, domain.ErrNotFound):
+			w.WriteHeader(http.StatusNotFound)
+			utils.WriteJSON(w, domain.ErrorResponse("NOT_FOUND", "pull request or author not found"))
+		case errors.Is(err, domain.ErrPRMerged), errors.Is(err, domain.ErrPRClosed),
+			errors.Is(err, domain.ErrNotEnoughReviewers), errors.Is(err, domain.ErrNoCapacity), errors.Is(err, domain.ErrLeadRequired),
+			errors.Is(err, domain.ErrConcurrentUpdate), errors.Is(err, domain.ErrPRDraft):
+			w.WriteHeader(http.StatusConflict)
+			utils.WriteJSON(w, domain.ErrorResponse(err.Error(), "cannot apply "+event.Action+" to "+event.PullRequestID))
+		default:
+			log.Println(err)
+			w.WriteHeader(http.StatusInternalServerError)
+			utils.WriteJSON(w, domain.ErrorResponse("INTERNAL_ERROR", "failed to apply event"))
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	utils.WriteJSON(w, map[string]any{
+		"action": event.Action,
+		"pr":     pr,
+	})
+}
+
+// AddIdentity handles POST /integrations/identities/add
+func (h *IntegrationHandler) AddIdentity(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		utils.WriteJSON(w, domain.ErrorResponse("METHOD_NOT_ALLOWED", "method not allowed"))
+		return
+	}
+
+	var identity domain.Identity
+	if err := json.NewDecoder(r.Body).Decode(&identity); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		utils.WriteJSON(w, domain.ErrorResponse("INVALID_JSON", "invalid json body"))
+		return
+	}
+
+	if !domain.IsKnownProvider(identity.Provider) || identity.Login == "" || identity.UserID == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		utils.WriteJSON(w, domain.ErrorResponse("VALIDATION_ERROR", "provider (github, gitlab), login and user_id required"))
+		return
+	}
+
+	if err := h.Service.AddIdentity(&identity); err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			w.WriteHeader(http.StatusNotFound)
+			utils.WriteJSON(w, domain.ErrorResponse("NOT_FOUND", "user not found"))
+			return
+		}
+
+		log.Println(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		utils.WriteJSON(w, domain.ErrorResponse("INTERNAL_ERROR", "failed to save identity"))
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	utils.WriteJSON(w, map[string]any{
+		"identity": identity,
+	})
+}
+
+// ListIdentities handles GET /integrations/identities/list
+func (h *IntegrationHandler) ListIdentities(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		utils.WriteJSON(w, domain.ErrorResponse("METHOD_NOT_ALLOWED", "method not allowed"))
+		return
+	}
+
+	identities, err := h.Service.ListIdentities(r.URL.Query().Get("user_id"))
+	if err != nil {
+		log.Println(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		utils.WriteJSON(w, domain.ErrorResponse("INTERNAL_ERROR", "failed to list identities"))
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	utils.WriteJSON(w, map[string]any{
+		"identities": identities,
+	})
+}
+
+// DeleteIdentity handles POST /integrations/identities/delete
+func (h *IntegrationHandler) DeleteIdentity(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		utils.WriteJSON(w, domain.ErrorResponse("METHOD_NOT_ALLOWED", "method not allowed"))
+		return
+	}
+
+	var body struct {
+		Provider string `json:"provider"`
+		Login    string `json:"login"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		utils.WriteJSON(w, domain.ErrorResponse("INVALID_JSON", "invalid json body"))
+		return
+	}
+
+	if body.Provider == "" || body.Login == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		utils.WriteJSON(w, domain.ErrorResponse("VALIDATION_ERROR", "provider and login required"))
+		return
+	}
+
+	if err := h.Service.DeleteIdentity(body.Provider, body.Login); err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			w.WriteHeader(http.StatusNotFound)
+			utils.WriteJSON(w, domain.ErrorResponse("NOT_FOUND", "identity not found"))
+			return
+		}
+
+		log.Println(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		utils.WriteJSON(w, domain.ErrorResponse("INTERNAL_ERROR", "failed to delete identity"))
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	utils.WriteJSON(w, map[string]any{
+		"deleted": body,
+	})
+}
@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"pr-reviewer/internal/domain"
+	"pr-reviewer/internal/services"
+	"pr-reviewer/internal/utils"
+)
+
+type OwnershipHandler struct {
+	Service services.OwnershipService
+}
+
+func NewOwnershipHandler(s services.OwnershipService) *OwnershipHandler {
+	return &OwnershipHandler{Service: s}
+}
+
+// CreateRule handles POST /owners/add
+func (h *OwnershipHandler) CreateRule(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		utils.WriteJSON(w, domain.ErrorResponse("METHOD_NOT_ALLOWED", "method not allowed"))
+		return
+	}
+
+	var rule domain.OwnershipRule
+	if err := json.NewDecoder(r.Body).Decode(&rule); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		utils.WriteJSON(w, domain.ErrorResponse("INVALID_JSON", "invalid json body"))
+		return
+	}
+
+	if err := h.Service.CreateRule(&rule); err != nil {
+
+		switch {
+		case errors.Is(err, domain.ErrInvalidRule):
+			w.WriteHeader(http.StatusBadRequest)
+			utils.WriteJSON(w, domain.ErrorResponse("VALIDATION_ERROR", "pattern and exactly one of user_id, team_name required"))
+		case errors.Is(err, domain.ErrNotFound):
+			w.WriteHeader(http.StatusNotFound)
+			utils.WriteJSON(w, domain.ErrorResponse("NOT_FOUND", "user or team not found"))
+		default:
+			log.Println(err)
+			w.WriteHeader(http.StatusInternalServerError)
+			utils.WriteJSON(w, domain.ErrorResponse("INTERNAL_ERROR", "failed to create ownership rule"))
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	utils.WriteJSON(w, map[string]any{
+		"rule": rule,
+	})
+}
+
+// ListRules handles GET /owners/list
+func (h *OwnershipHandler) ListRules(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		utils.WriteJSON(w, domain.ErrorResponse("METHOD_NOT_ALLOWED", "method not allowed"))
+		return
+	}
+
+	rules, err := h.Service.ListRules()
+	if err != nil {
+		log.Println(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		utils.WriteJSON(w, domain.ErrorResponse("INTERNAL_ERROR", "failed to list ownership rules"))
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	utils.WriteJSON(w, map[string]any{
+		"rules": rules,
+	})
+}
+
+// DeleteRule handles POST /owners/delete
+func (h *OwnershipHandler) DeleteRule(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		utils.WriteJSON(w, domain.ErrorResponse("METHOD_NOT_ALLOWED", "method not allowed"))
+		return
+	}
+
+	var body struct {
+		ID int64 `json:"rule_id"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		utils.WriteJSON(w, domain.ErrorResponse("INVALID_JSON", "invalid json body"))
+		return
+	}
+
+	if body.ID == 0 {
+		w.WriteHeader(http.StatusBadRequest)
+		utils.WriteJSON(w, domain.ErrorResponse("VALIDATION_ERROR", "rule_id is required"))
+		return
+	}
+
+	if err := h.Service.DeleteRule(body.ID); err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			w.WriteHeader(http.StatusNotFound)
+			utils.WriteJSON(w, domain.ErrorResponse("NOT_FOUND", "ownership rule not found"))
+			return
+		}
+
+		log.Println(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		utils.WriteJSON(w, domain.ErrorResponse("INTERNAL_ERROR", "failed to delete ownership rule"))
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	utils.WriteJSON(w, map[string]any{
+		"deleted": body.ID,
+	})
+}
@@ -1,20 +1,27 @@
 package handlers
 
 import (
+	"crypto/subtle"
 	"encoding/json"
 	"errors"
+	"log"
 	"net/http"
 	"pr-reviewer/internal/domain"
 	"pr-reviewer/internal/services"
 	"pr-reviewer/internal/utils"
 )
 
+const concurrentUpdateMessage = "PR status was changed by another request, reload and retry"
+
+const leadRequiredMessage = "team lead_rule requires a lead among reviewers, but no team lead is available"
+
 type PullRequestHandler struct {
-	Service services.PullRequestService
+	Service    services.PullRequestService
+	AdminToken string // для merge с force; пусто — force запрещён
 }
 
-func NewPullRequestHandler(s services.PullRequestService) *PullRequestHandler {
-	return &PullRequestHandler{Service: s}
+func NewPullRequestHandler(s services.PullRequestService, adminToken string) *PullRequestHandler {
+	return &PullRequestHandler{Service: s, AdminToken: adminToken}
 }
 
 func (h *PullRequestHandler) CreatePR(w http.ResponseWriter, r *http.Request) {
@@ -25,9 +32,12 @@ func (h *PullRequestHandler) CreatePR(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var body struct {
-		ID     string `json:"pull_request_id"`
-		Name   string `json:"pull_request_name"`
-		Author string `json:"author_id"`
+		ID     string   `json:"pull_request_id"`
+		Name   string   `json:"pull_request_name"`
+		Author string   `json:"author_id"`
+		Draft  bool     `json:"draft"`
+		Files  []string `json:"changed_files"`
+		Team   string   `json:"team_name"` // целевая команда; по умолчанию основная команда автора
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
@@ -43,12 +53,23 @@ func (h *PullRequestHandler) CreatePR(w http.ResponseWriter, r *http.Request) {
 	}
 
 	pr := &domain.PullRequest{
-		ID:       body.ID,
-		Name:     body.Name,
-		AuthorID: body.Author,
+		ID:           body.ID,
+		Name:         body.Name,
+		AuthorID:     body.Author,
+		ChangedFiles: body.Files,
+		TeamName:     body.Team,
+	}
+	if body.Draft {
+		pr.Status = domain.StatusDraft
+	}
+
+	// по умолчанию PR создаёт сам автор
+	actor := actorFrom(r)
+	if actor == "" {
+		actor = body.Author
 	}
 
-	created, err := h.Service.Create(pr)
+	created, err := h.Service.Create(pr, actor)
 	if err != nil {
 
 		if errors.Is(err, domain.ErrPRExists) {
@@ -61,6 +82,21 @@ func (h *PullRequestHandler) CreatePR(w http.ResponseWriter, r *http.Request) {
 			utils.WriteJSON(w, domain.ErrorResponse("NOT_FOUND", "author or team not found"))
 			return
 		}
+		if errors.Is(err, domain.ErrNotEnoughReviewers) {
+			w.WriteHeader(http.StatusConflict)
+			utils.WriteJSON(w, domain.ErrorResponse("NOT_ENOUGH_REVIEWERS", "team has fewer active reviewers than min_reviewers"))
+			return
+		}
+		if errors.Is(err, domain.ErrNoCapacity) {
+			w.WriteHeader(http.StatusConflict)
+			utils.WriteJSON(w, domain.ErrorResponse("NO_CAPACITY", "not enough reviewers below max_open_reviews to satisfy min_reviewers"))
+			return
+		}
+		if errors.Is(err, domain.ErrLeadRequired) {
+			w.WriteHeader(http.StatusConflict)
+			utils.WriteJSON(w, domain.ErrorResponse("LEAD_REQUIRED", leadRequiredMessage))
+			return
+		}
 
 		w.WriteHeader(http.StatusInternalServerError)
 		utils.WriteJSON(w, domain.ErrorResponse("INTERNAL_ERROR", "failed to create PR"))
@@ -81,7 +117,8 @@ func (h *PullRequestHandler) Merge(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var body struct {
-		ID string `json:"pull_request_id"`
+		ID    string `json:"pull_request_id"`
+		Force bool   `json:"force"`
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
@@ -96,7 +133,13 @@ func (h *PullRequestHandler) Merge(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	pr, err := h.Service.Merge(body.ID)
+	if body.Force && !h.isAdmin(r) {
+		w.WriteHeader(http.StatusForbidden)
+		utils.WriteJSON(w, domain.ErrorResponse("FORBIDDEN", "force merge requires admin token"))
+		return
+	}
+
+	pr, err := h.Service.Merge(body.ID, body.Force, actorFrom(r))
 	if err != nil {
 
 		if errors.Is(err, domain.ErrNotFound) {
@@ -105,6 +148,30 @@ func (h *PullRequestHandler) Merge(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
+		if errors.Is(err, domain.ErrMergeBlocked) {
+			w.WriteHeader(http.StatusConflict)
+			utils.WriteJSON(w, domain.ErrorResponse("MERGE_BLOCKED", err.Error()))
+			return
+		}
+
+		if errors.Is(err, domain.ErrPRDraft) {
+			w.WriteHeader(http.StatusConflict)
+			utils.WriteJSON(w, domain.ErrorResponse("PR_DRAFT", "only OPEN PR can be merged, mark draft as ready first"))
+			return
+		}
+
+		if errors.Is(err, domain.ErrPRClosed) {
+			w.WriteHeader(http.StatusConflict)
+			utils.WriteJSON(w, domain.ErrorResponse("PR_CLOSED", "only OPEN PR can be merged, reopen it first"))
+			return
+		}
+
+		if errors.Is(err, domain.ErrConcurrentUpdate) {
+			w.WriteHeader(http.StatusConflict)
+			utils.WriteJSON(w, domain.ErrorResponse("CONCURRENT_UPDATE", concurrentUpdateMessage))
+			return
+		}
+
 		w.WriteHeader(http.StatusInternalServerError)
 		utils.WriteJSON(w, domain.ErrorResponse("INTERNAL_ERROR", "failed to merge PR"))
 		return
@@ -126,6 +193,7 @@ func (h *PullRequestHandler) Reassign(w http.ResponseWriter, r *http.Request) {
 	var body struct {
 		ID    string `json:"pull_request_id"`
 		OldID string `json:"old_user_id"`
+		NewID string `json:"new_user_id"` // необязательно: иначе замена подбирается автоматически
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
@@ -140,7 +208,7 @@ func (h *PullRequestHandler) Reassign(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	pr, replaced, err := h.Service.Reassign(body.ID, body.OldID)
+	pr, replaced, err := h.Service.Reassign(body.ID, body.OldID, body.NewID, actorFrom(r))
 	if err != nil {
 
 		switch {
@@ -150,12 +218,24 @@ func (h *PullRequestHandler) Reassign(w http.ResponseWriter, r *http.Request) {
 		case errors.Is(err, domain.ErrPRMerged):
 			w.WriteHeader(http.StatusConflict)
 			utils.WriteJSON(w, domain.ErrorResponse("PR_MERGED", "cannot reassign on merged PR"))
+		case errors.Is(err, domain.ErrPRClosed):
+			w.WriteHeader(http.StatusConflict)
+			utils.WriteJSON(w, domain.ErrorResponse("PR_CLOSED", "cannot reassign on closed PR"))
 		case errors.Is(err, domain.ErrNotAssigned):
 			w.WriteHeader(http.StatusConflict)
 			utils.WriteJSON(w, domain.ErrorResponse("NOT_ASSIGNED", "reviewer is not assigned to this PR"))
 		case errors.Is(err, domain.ErrNoCandidate):
 			w.WriteHeader(http.StatusConflict)
 			utils.WriteJSON(w, domain.ErrorResponse("NO_CANDIDATE", "no active replacement candidate in team"))
+		case errors.Is(err, domain.ErrNoCapacity):
+			w.WriteHeader(http.StatusConflict)
+			utils.WriteJSON(w, domain.ErrorResponse("NO_CAPACITY", "all replacement candidates reached max_open_reviews"))
+		case errors.Is(err, domain.ErrLeadRequired):
+			w.WriteHeader(http.StatusConflict)
+			utils.WriteJSON(w, domain.ErrorResponse("LEAD_REQUIRED", "the only assigned team lead must be replaced by another available lead"))
+		case errors.Is(err, domain.ErrSelfReview), errors.Is(err, domain.ErrUserInactive), errors.Is(err, domain.ErrUserUnavailable), errors.Is(err, domain.ErrAlreadyAssigned):
+			w.WriteHeader(http.StatusConflict)
+			utils.WriteJSON(w, domain.ErrorResponse(err.Error(), manualReviewerMessage(err)))
 		default:
 			w.WriteHeader(http.StatusInternalServerError)
 			utils.WriteJSON(w, domain.ErrorResponse("INTERNAL_ERROR", "failed to reassign reviewer"))
@@ -170,6 +250,101 @@ func (h *PullRequestHandler) Reassign(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// AddReviewer handles POST /pullRequest/reviewers/add
+func (h *PullRequestHandler) AddReviewer(w http.ResponseWriter, r *http.Request) {
+	h.changeReviewer(w, r, func(prID, userID string, _ bool, actor string) (*domain.PullRequest, error) {
+		return h.Service.AddReviewer(prID, userID, actor)
+	})
+}
+
+// RemoveReviewer handles POST /pullRequest/reviewers/remove
+func (h *PullRequestHandler) RemoveReviewer(w http.ResponseWriter, r *http.Request) {
+	h.changeReviewer(w, r, h.Service.RemoveReviewer)
+}
+
+// общий обработчик ручного изменения ревьюверов по pull_request_id и user_id
+func (h *PullRequestHandler) changeReviewer(w http.ResponseWriter, r *http.Request, apply func(prID, userID string, force bool, actor string) (*domain.PullRequest, error)) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		utils.WriteJSON(w, domain.ErrorResponse("METHOD_NOT_ALLOWED", "method not allowed"))
+		return
+	}
+
+	var body struct {
+		ID     string `json:"pull_request_id"`
+		UserID string `json:"user_id"`
+		Force  bool   `json:"force"` // снять ревьювера ниже min_reviewers, только с admin-токеном
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		utils.WriteJSON(w, domain.ErrorResponse("INVALID_JSON", "invalid json body"))
+		return
+	}
+
+	if body.ID == "" || body.UserID == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		utils.WriteJSON(w, domain.ErrorResponse("VALIDATION_ERROR", "pull_request_id and user_id required"))
+		return
+	}
+
+	if body.Force && !h.isAdmin(r) {
+		w.WriteHeader(http.StatusForbidden)
+		utils.WriteJSON(w, domain.ErrorResponse("FORBIDDEN", "force requires admin token"))
+		return
+	}
+
+	pr, err := apply(body.ID, body.UserID, body.Force, actorFrom(r))
+	if err != nil {
+
+		switch {
+		case errors.Is(err, domain.ErrNotFound):
+			w.WriteHeader(http.StatusNotFound)
+			utils.WriteJSON(w, domain.ErrorResponse("NOT_FOUND", "pr or user not found"))
+		case errors.Is(err, domain.ErrPRMerged):
+			w.WriteHeader(http.StatusConflict)
+			utils.WriteJSON(w, domain.ErrorResponse("PR_MERGED", "cannot change reviewers on merged PR"))
+		case errors.Is(err, domain.ErrPRClosed):
+			w.WriteHeader(http.StatusConflict)
+			utils.WriteJSON(w, domain.ErrorResponse("PR_CLOSED", "cannot change reviewers on closed PR"))
+		case errors.Is(err, domain.ErrPRDraft):
+			w.WriteHeader(http.StatusConflict)
+			utils.WriteJSON(w, domain.ErrorResponse("PR_DRAFT", "reviewers are assigned when PR is ready"))
+		case errors.Is(err, domain.ErrNotAssigned):
+			w.WriteHeader(http.StatusConflict)
+			utils.WriteJSON(w, domain.ErrorResponse("NOT_ASSIGNED", "reviewer is not assigned to this PR"))
+		case errors.Is(err, domain.ErrNotEnoughReviewers):
+			w.WriteHeader(http.StatusConflict)
+			utils.WriteJSON(w, domain.ErrorResponse("NOT_ENOUGH_REVIEWERS", "removing reviewer would leave fewer than min_reviewers"))
+		case errors.Is(err, domain.ErrSelfReview), errors.Is(err, domain.ErrUserInactive), errors.Is(err, domain.ErrUserUnavailable), errors.Is(err, domain.ErrAlreadyAssigned):
+			w.WriteHeader(http.StatusConflict)
+			utils.WriteJSON(w, domain.ErrorResponse(err.Error(), manualReviewerMessage(err)))
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+			utils.WriteJSON(w, domain.ErrorResponse("INTERNAL_ERROR", "failed to update reviewers"))
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	utils.WriteJSON(w, map[string]any{
+		"pr": pr,
+	})
+}
+
+func manualReviewerMessage(err error) string {
+	switch {
+	case errors.Is(err, domain.ErrSelfReview):
+		return "author cannot review own PR"
+	case errors.Is(err, domain.ErrUserInactive):
+		return "user is not active"
+	case errors.Is(err, domain.ErrUserUnavailable):
+		return "user is unavailable now"
+	default:
+		return "user is already assigned to this PR"
+	}
+}
+
 func (h *PullRequestHandler) GetReview(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		w.WriteHeader(http.StatusMethodNotAllowed)
@@ -177,7 +352,8 @@ func (h *PullRequestHandler) GetReview(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	userID := r.URL.Query().Get("user_id")
+	q := r.URL.Query()
+	userID := q.Get("user_id")
 
 	if userID == "" {
 		w.WriteHeader(http.StatusBadRequest)
@@ -185,23 +361,344 @@ func (h *PullRequestHandler) GetReview(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	prs, err := h.Service.GetReview(userID)
+	createdAfter, okAfter := queryTime(q, "created_after")
+	createdBefore, okBefore := queryTime(q, "created_before")
+	limit, okLimit := queryInt(q, "limit")
+	if !okAfter || !okBefore || !okLimit {
+		w.WriteHeader(http.StatusBadRequest)
+		utils.WriteJSON(w, domain.ErrorResponse("VALIDATION_ERROR", "created_after/created_before must be RFC3339 or YYYY-MM-DD, limit must be a number"))
+		return
+	}
+
+	page, err := h.Service.GetReview(&domain.ReviewFilter{
+		UserID:        userID,
+		Statuses:      queryList(q, "status"),
+		CreatedAfter:  createdAfter,
+		CreatedBefore: createdBefore,
+		Sort:          q.Get("sort"),
+		Limit:         limit,
+		Cursor:        q.Get("cursor"),
+	})
+	if err != nil {
+
+		switch {
+		case errors.Is(err, domain.ErrNotFound):
+			w.WriteHeader(http.StatusNotFound)
+			utils.WriteJSON(w, domain.ErrorResponse("NOT_FOUND", "user not found"))
+		case errors.Is(err, domain.ErrInvalidFilter):
+			w.WriteHeader(http.StatusBadRequest)
+			utils.WriteJSON(w, domain.ErrorResponse("VALIDATION_ERROR", "status must be DRAFT, OPEN, MERGED or CLOSED, sort created_at or -created_at, limit 1..100, created_after before created_before"))
+		case errors.Is(err, domain.ErrInvalidCursor):
+			w.WriteHeader(http.StatusBadRequest)
+			utils.WriteJSON(w, domain.ErrorResponse("INVALID_CURSOR", "cursor is malformed or issued for another sort"))
+		default:
+			log.Println(err)
+			w.WriteHeader(http.StatusInternalServerError)
+			utils.WriteJSON(w, domain.ErrorResponse("INTERNAL_ERROR", "failed to get pull requests"))
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	utils.WriteJSON(w, map[string]any{
+		"user_id":       userID,
+		"pull_requests": page.PullRequests,
+		"next_cursor":   page.NextCursor,
+	})
+}
+
+// Get handles GET /pullRequest/get
+func (h *PullRequestHandler) Get(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		utils.WriteJSON(w, domain.ErrorResponse("METHOD_NOT_ALLOWED", "method not allowed"))
+		return
+	}
+
+	prID := r.URL.Query().Get("pull_request_id")
+	if prID == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		utils.WriteJSON(w, domain.ErrorResponse("VALIDATION_ERROR", "pull_request_id is required"))
+		return
+	}
+
+	pr, err := h.Service.Get(prID)
 	if err != nil {
 
 		if errors.Is(err, domain.ErrNotFound) {
 			w.WriteHeader(http.StatusNotFound)
-			utils.WriteJSON(w, domain.ErrorResponse("NOT_FOUND", "no pull requests found"))
+			utils.WriteJSON(w, domain.ErrorResponse("NOT_FOUND", "pull request not found"))
 			return
 		}
 
+		log.Println(err)
 		w.WriteHeader(http.StatusInternalServerError)
-		utils.WriteJSON(w, domain.ErrorResponse("INTERNAL_ERROR", "failed to get pull requests"))
+		utils.WriteJSON(w, domain.ErrorResponse("INTERNAL_ERROR", "failed to get pull request"))
+		return
+	}
+
+	body, err := json.Marshal(map[string]any{
+		"pr": pr,
+	})
+	if err != nil {
+		log.Println(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		utils.WriteJSON(w, domain.ErrorResponse("INTERNAL_ERROR", "failed to encode pull request"))
+		return
+	}
+
+	// ETag по телу: меняется при любом изменении статуса, ревьюверов или вердиктов
+	etag := utils.ETag(body)
+	w.Header().Set("ETag", etag)
+
+	if match := r.Header.Get("If-None-Match"); match != "" && utils.ETagMatches(match, etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(append(body, '\n')); err != nil {
+		log.Println("write response:", err)
+	}
+}
+
+// List handles GET /pullRequest/list
+func (h *PullRequestHandler) List(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		utils.WriteJSON(w, domain.ErrorResponse("METHOD_NOT_ALLOWED", "method not allowed"))
+		return
+	}
+
+	q := r.URL.Query()
+
+	createdAfter, okCreatedAfter := queryTime(q, "created_after")
+	createdBefore, okCreatedBefore := queryTime(q, "created_before")
+	mergedAfter, okMergedAfter := queryTime(q, "merged_after")
+	mergedBefore, okMergedBefore := queryTime(q, "merged_before")
+	limit, okLimit := queryInt(q, "limit")
+	if !okCreatedAfter || !okCreatedBefore || !okMergedAfter || !okMergedBefore || !okLimit {
+		w.WriteHeader(http.StatusBadRequest)
+		utils.WriteJSON(w, domain.ErrorResponse("VALIDATION_ERROR", "date filters must be RFC3339 or YYYY-MM-DD, limit must be a number"))
+		return
+	}
+
+	filter := &domain.PullRequestFilter{
+		AuthorID:      q.Get("author_id"),
+		TeamName:      q.Get("team_name"),
+		ReviewerID:    q.Get("reviewer_id"),
+		Statuses:      queryList(q, "status"),
+		Title:         q.Get("title"),
+		CreatedAfter:  createdAfter,
+		CreatedBefore: createdBefore,
+		MergedAfter:   mergedAfter,
+		MergedBefore:  mergedBefore,
+		Sort:          q.Get("sort"),
+		Limit:         limit,
+		Cursor:        q.Get("cursor"),
+	}
+
+	page, err := h.Service.List(filter)
+	if err != nil {
+
+		switch {
+		case errors.Is(err, domain.ErrInvalidFilter):
+			w.WriteHeader(http.StatusBadRequest)
+			utils.WriteJSON(w, domain.ErrorResponse("VALIDATION_ERROR", "status must be DRAFT, OPEN, MERGED or CLOSED, sort created_at or -created_at, limit 1..100, *_after before *_before"))
+		case errors.Is(err, domain.ErrInvalidCursor):
+			w.WriteHeader(http.StatusBadRequest)
+			utils.WriteJSON(w, domain.ErrorResponse("INVALID_CURSOR", "cursor is malformed or issued for another sort"))
+		default:
+			log.Println(err)
+			w.WriteHeader(http.StatusInternalServerError)
+			utils.WriteJSON(w, domain.ErrorResponse("INTERNAL_ERROR", "failed to list pull requests"))
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	utils.WriteJSON(w, page)
+}
+
+// History handles GET /pullRequest/history
+func (h *PullRequestHandler) History(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		utils.WriteJSON(w, domain.ErrorResponse("METHOD_NOT_ALLOWED", "method not allowed"))
+		return
+	}
+
+	prID := r.URL.Query().Get("pull_request_id")
+	if prID == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		utils.WriteJSON(w, domain.ErrorResponse("VALIDATION_ERROR", "pull_request_id is required"))
+		return
+	}
+
+	events, err := h.Service.History(prID)
+	if err != nil {
+
+		if errors.Is(err, domain.ErrNotFound) {
+			w.WriteHeader(http.StatusNotFound)
+			utils.WriteJSON(w, domain.ErrorResponse("NOT_FOUND", "pull request not found"))
+			return
+		}
+
+		w.WriteHeader(http.StatusInternalServerError)
+		utils.WriteJSON(w, domain.ErrorResponse("INTERNAL_ERROR", "failed to get history"))
 		return
 	}
 
 	w.WriteHeader(http.StatusOK)
 	utils.WriteJSON(w, map[string]any{
-		"user_id":       userID,
-		"pull_requests": prs,
+		"pull_request_id": prID,
+		"events":          events,
+	})
+}
+
+func (h *PullRequestHandler) Review(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		utils.WriteJSON(w, domain.ErrorResponse("METHOD_NOT_ALLOWED", "method not allowed"))
+		return
+	}
+
+	var body struct {
+		ID         string `json:"pull_request_id"`
+		ReviewerID string `json:"reviewer_id"`
+		Verdict    string `json:"verdict"`
+		Comment    string `json:"comment"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		utils.WriteJSON(w, domain.ErrorResponse("INVALID_JSON", "invalid json body"))
+		return
+	}
+
+	if body.ID == "" || body.ReviewerID == "" || !domain.IsValidVerdict(body.Verdict) {
+		w.WriteHeader(http.StatusBadRequest)
+		utils.WriteJSON(w, domain.ErrorResponse("VALIDATION_ERROR", "pull_request_id, reviewer_id and verdict (APPROVED, CHANGES_REQUESTED, COMMENTED) required"))
+		return
+	}
+
+	pr, err := h.Service.Review(&domain.Review{
+		PullRequestID: body.ID,
+		ReviewerID:    body.ReviewerID,
+		Verdict:       body.Verdict,
+		Comment:       body.Comment,
+	})
+	if err != nil {
+
+		switch {
+		case errors.Is(err, domain.ErrNotFound):
+			w.WriteHeader(http.StatusNotFound)
+			utils.WriteJSON(w, domain.ErrorResponse("NOT_FOUND", "pull request not found"))
+		case errors.Is(err, domain.ErrPRMerged):
+			w.WriteHeader(http.StatusConflict)
+			utils.WriteJSON(w, domain.ErrorResponse("PR_MERGED", "cannot review merged PR"))
+		case errors.Is(err, domain.ErrPRClosed):
+			w.WriteHeader(http.StatusConflict)
+			utils.WriteJSON(w, domain.ErrorResponse("PR_CLOSED", "cannot review closed PR"))
+		case errors.Is(err, domain.ErrNotAssigned):
+			w.WriteHeader(http.StatusConflict)
+			utils.WriteJSON(w, domain.ErrorResponse("NOT_ASSIGNED", "reviewer is not assigned to this PR"))
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+			utils.WriteJSON(w, domain.ErrorResponse("INTERNAL_ERROR", "failed to save review"))
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	utils.WriteJSON(w, map[string]any{
+		"pr": pr,
+	})
+}
+
+func (h *PullRequestHandler) isAdmin(r *http.Request) bool {
+	token := r.Header.Get("X-Admin-Token")
+	return h.AdminToken != "" && subtle.ConstantTimeCompare([]byte(token), []byte(h.AdminToken)) == 1
+}
+
+// actorFrom — кто выполняет запрос (user_id из заголовка X-Actor), пишется в журнал событий
+func actorFrom(r *http.Request) string {
+	return r.Header.Get("X-Actor")
+}
+
+// Close handles POST /pullRequest/close
+func (h *PullRequestHandler) Close(w http.ResponseWriter, r *http.Request) {
+	h.transition(w, r, h.Service.Close)
+}
+
+// Reopen handles POST /pullRequest/reopen
+func (h *PullRequestHandler) Reopen(w http.ResponseWriter, r *http.Request) {
+	h.transition(w, r, h.Service.Reopen)
+}
+
+// Ready handles POST /pullRequest/ready
+func (h *PullRequestHandler) Ready(w http.ResponseWriter, r *http.Request) {
+	h.transition(w, r, h.Service.Ready)
+}
+
+// общий обработчик смены статуса PR по pull_request_id
+func (h *PullRequestHandler) transition(w http.ResponseWriter, r *http.Request, apply func(prID, actor string) (*domain.PullRequest, error)) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		utils.WriteJSON(w, domain.ErrorResponse("METHOD_NOT_ALLOWED", "method not allowed"))
+		return
+	}
+
+	var body struct {
+		ID string `json:"pull_request_id"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		utils.WriteJSON(w, domain.ErrorResponse("INVALID_JSON", "invalid json body"))
+		return
+	}
+
+	if body.ID == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		utils.WriteJSON(w, domain.ErrorResponse("VALIDATION_ERROR", "pull_request_id is required"))
+		return
+	}
+
+	pr, err := apply(body.ID, actorFrom(r))
+	if err != nil {
+
+		switch {
+		case errors.Is(err, domain.ErrNotFound):
+			w.WriteHeader(http.StatusNotFound)
+			utils.WriteJSON(w, domain.ErrorResponse("NOT_FOUND", "pull request not found"))
+		case errors.Is(err, domain.ErrPRMerged):
+			w.WriteHeader(http.StatusConflict)
+			utils.WriteJSON(w, domain.ErrorResponse("PR_MERGED", "PR is already merged"))
+		case errors.Is(err, domain.ErrPRClosed):
+			w.WriteHeader(http.StatusConflict)
+			utils.WriteJSON(w, domain.ErrorResponse("PR_CLOSED", "PR is closed, reopen it first"))
+		case errors.Is(err, domain.ErrNotEnoughReviewers):
+			w.WriteHeader(http.StatusConflict)
+			utils.WriteJSON(w, domain.ErrorResponse("NOT_ENOUGH_REVIEWERS", "team has fewer active reviewers than min_reviewers"))
+		case errors.Is(err, domain.ErrNoCapacity):
+			w.WriteHeader(http.StatusConflict)
+			utils.WriteJSON(w, domain.ErrorResponse("NO_CAPACITY", "not enough reviewers below max_open_reviews to satisfy min_reviewers"))
+		case errors.Is(err, domain.ErrLeadRequired):
+			w.WriteHeader(http.StatusConflict)
+			utils.WriteJSON(w, domain.ErrorResponse("LEAD_REQUIRED", leadRequiredMessage))
+		case errors.Is(err, domain.ErrConcurrentUpdate):
+			w.WriteHeader(http.StatusConflict)
+			utils.WriteJSON(w, domain.ErrorResponse("CONCURRENT_UPDATE", concurrentUpdateMessage))
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+			utils.WriteJSON(w, domain.ErrorResponse("INTERNAL_ERROR", "failed to update PR status"))
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	utils.WriteJSON(w, map[string]any{
+		"pr": pr,
 	})
 }
@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// queryList — "OPEN,DRAFT" -> [OPEN DRAFT]
+func queryList(q url.Values, key string) []string {
+	var res []string
+	for _, v := range strings.Split(q.Get(key), ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			res = append(res, v)
+		}
+	}
+	return res
+}
+
+// queryTime — RFC3339 или дата YYYY-MM-DD (UTC); nil, если параметр не задан
+func queryTime(q url.Values, key string) (*time.Time, bool) {
+	raw := q.Get(key)
+	if raw == "" {
+		return nil, true
+	}
+
+	for _, layout := range []string{time.RFC3339, time.DateOnly} {
+		if t, err := time.Parse(layout, raw); err == nil {
+			t = t.UTC()
+			return &t, true
+		}
+	}
+	return nil, false
+}
+
+// queryInt — 0, если параметр не задан
+func queryInt(q url.Values, key string) (int, bool) {
+	raw := q.Get(key)
+	if raw == "" {
+		return 0, true
+	}
+
+	v, err := strconv.Atoi(raw)
+	return v, err == nil
+}
+
+// queryBool — nil, если параметр не задан
+func queryBool(q url.Values, key string) (*bool, bool) {
+	raw := q.Get(key)
+	if raw == "" {
+		return nil, true
+	}
+
+	v, err := strconv.ParseBool(raw)
+	if err != nil {
+		return nil, false
+	}
+	return &v, true
+}
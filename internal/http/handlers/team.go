@@ -10,6 +10,10 @@ import (
 	"pr-reviewer/internal/utils"
 )
 
+const invalidSettingsMessage = "reviewers_count, min_reviewers and required_approvals must be non-negative, min_reviewers <= reviewers_count, strategy one of random, round_robin, least_loaded, fallback_teams must be other existing teams, escalation one of none, siblings, parent, siblings_parent, lead_rule one of none, always, title (title requires a valid lead_title_pattern regexp)"
+
+const invalidRoleMessage = "role must be member or lead"
+
 type TeamHandler struct {
 	Service services.TeamService
 }
@@ -47,6 +51,24 @@ func (h *TeamHandler) CreateTeam(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
+		if errors.Is(err, domain.ErrInvalidSettings) {
+			w.WriteHeader(http.StatusBadRequest)
+			utils.WriteJSON(w, domain.ErrorResponse("VALIDATION_ERROR", invalidSettingsMessage))
+			return
+		}
+
+		if errors.Is(err, domain.ErrInvalidRole) {
+			w.WriteHeader(http.StatusBadRequest)
+			utils.WriteJSON(w, domain.ErrorResponse("VALIDATION_ERROR", invalidRoleMessage))
+			return
+		}
+
+		if errors.Is(err, domain.ErrNotFound) {
+			w.WriteHeader(http.StatusNotFound)
+			utils.WriteJSON(w, domain.ErrorResponse("NOT_FOUND", "parent team not found"))
+			return
+		}
+
 		log.Println(err)
 		w.WriteHeader(http.StatusInternalServerError)
 		utils.WriteJSON(w, domain.ErrorResponse("INTERNAL_ERROR", "create team failed"))
@@ -65,6 +87,46 @@ func (h *TeamHandler) GetTeam(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	q := r.URL.Query()
+
+	teamName := q.Get("team_name")
+	withSubteams, ok := queryBool(q, "include_subteams")
+	if teamName == "" || !ok {
+		w.WriteHeader(http.StatusBadRequest)
+		utils.WriteJSON(w, domain.ErrorResponse("VALIDATION_ERROR", "team_name is required, include_subteams must be true or false"))
+		return
+	}
+
+	team, err := h.Service.GetTeam(teamName, withSubteams != nil && *withSubteams)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			w.WriteHeader(http.StatusNotFound)
+			utils.WriteJSON(w, domain.ErrorResponse("NOT_FOUND", "team not found"))
+			return
+		}
+
+		log.Println(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		utils.WriteJSON(w, domain.ErrorResponse("INTERNAL_ERROR", "failed to get team"))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+
+	utils.WriteJSON(w, map[string]any{
+		"team": team,
+	})
+}
+
+// GetSettings handles GET /team/settings/get
+func (h *TeamHandler) GetSettings(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		utils.WriteJSON(w, domain.ErrorResponse("METHOD_NOT_ALLOWED", "method not allowed"))
+		return
+	}
+
 	teamName := r.URL.Query().Get("team_name")
 	if teamName == "" {
 		w.WriteHeader(http.StatusBadRequest)
@@ -72,7 +134,7 @@ func (h *TeamHandler) GetTeam(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	team, err := h.Service.GetTeam(teamName)
+	settings, err := h.Service.GetSettings(teamName)
 	if err != nil {
 		if errors.Is(err, domain.ErrNotFound) {
 			w.WriteHeader(http.StatusNotFound)
@@ -82,14 +144,316 @@ func (h *TeamHandler) GetTeam(w http.ResponseWriter, r *http.Request) {
 
 		log.Println(err)
 		w.WriteHeader(http.StatusInternalServerError)
-		utils.WriteJSON(w, domain.ErrorResponse("INTERNAL_ERROR", "failed to get team"))
+		utils.WriteJSON(w, domain.ErrorResponse("INTERNAL_ERROR", "failed to get team settings"))
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	utils.WriteJSON(w, map[string]any{
+		"team_name": teamName,
+		"settings":  settings,
+	})
+}
+
+// UpdateSettings handles POST /team/settings/update
+func (h *TeamHandler) UpdateSettings(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		utils.WriteJSON(w, domain.ErrorResponse("METHOD_NOT_ALLOWED", "method not allowed"))
+		return
+	}
+
+	var body struct {
+		TeamName string `json:"team_name"`
+		domain.TeamSettingsUpdate
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		utils.WriteJSON(w, domain.ErrorResponse("INVALID_JSON", "invalid json body"))
+		return
+	}
+
+	if body.TeamName == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		utils.WriteJSON(w, domain.ErrorResponse("VALIDATION_ERROR", "team_name is required"))
+		return
+	}
+
+	settings, err := h.Service.UpdateSettings(body.TeamName, &body.TeamSettingsUpdate)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrNotFound):
+			w.WriteHeader(http.StatusNotFound)
+			utils.WriteJSON(w, domain.ErrorResponse("NOT_FOUND", "team not found"))
+		case errors.Is(err, domain.ErrInvalidSettings):
+			w.WriteHeader(http.StatusBadRequest)
+			utils.WriteJSON(w, domain.ErrorResponse("VALIDATION_ERROR", invalidSettingsMessage))
+		default:
+			log.Println(err)
+			w.WriteHeader(http.StatusInternalServerError)
+			utils.WriteJSON(w, domain.ErrorResponse("INTERNAL_ERROR", "failed to update team settings"))
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	utils.WriteJSON(w, map[string]any{
+		"team_name": body.TeamName,
+		"settings":  settings,
+	})
+}
+
+// AddMember handles POST /team/members/add
+func (h *TeamHandler) AddMember(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		utils.WriteJSON(w, domain.ErrorResponse("METHOD_NOT_ALLOWED", "method not allowed"))
+		return
+	}
+
+	var body struct {
+		TeamName       string `json:"team_name"`
+		UserID         string `json:"user_id"`
+		UserName       string `json:"username"`
+		MaxOpenReviews *int   `json:"max_open_reviews"`
+		Primary        bool   `json:"primary"`
+		Role           string `json:"role"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		utils.WriteJSON(w, domain.ErrorResponse("INVALID_JSON", "invalid json body"))
+		return
+	}
+
+	if body.TeamName == "" || body.UserID == "" || body.UserName == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		utils.WriteJSON(w, domain.ErrorResponse("VALIDATION_ERROR", "team_name, user_id and username required"))
+		return
+	}
+
+	user := &domain.User{ID: body.UserID, UserName: body.UserName, MaxOpenReviews: body.MaxOpenReviews, Role: body.Role}
+
+	userDTO, err := h.Service.AddMember(body.TeamName, user, body.Primary, actorFrom(r))
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrNotFound):
+			w.WriteHeader(http.StatusNotFound)
+			utils.WriteJSON(w, domain.ErrorResponse("NOT_FOUND", "team not found"))
+		case errors.Is(err, domain.ErrInvalidCapacity):
+			w.WriteHeader(http.StatusBadRequest)
+			utils.WriteJSON(w, domain.ErrorResponse("VALIDATION_ERROR", "max_open_reviews must be >= 0 or null"))
+		case errors.Is(err, domain.ErrInvalidRole):
+			w.WriteHeader(http.StatusBadRequest)
+			utils.WriteJSON(w, domain.ErrorResponse("VALIDATION_ERROR", invalidRoleMessage))
+		default:
+			log.Println(err)
+			w.WriteHeader(http.StatusInternalServerError)
+			utils.WriteJSON(w, domain.ErrorResponse("INTERNAL_ERROR", "failed to add team member"))
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	utils.WriteJSON(w, map[string]any{
+		"user": userDTO,
+	})
+}
+
+// RemoveMember handles POST /team/members/remove
+func (h *TeamHandler) RemoveMember(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		utils.WriteJSON(w, domain.ErrorResponse("METHOD_NOT_ALLOWED", "method not allowed"))
+		return
+	}
+
+	var body struct {
+		TeamName string `json:"team_name"`
+		UserID   string `json:"user_id"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		utils.WriteJSON(w, domain.ErrorResponse("INVALID_JSON", "invalid json body"))
+		return
+	}
+
+	if body.TeamName == "" || body.UserID == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		utils.WriteJSON(w, domain.ErrorResponse("VALIDATION_ERROR", "team_name and user_id required"))
+		return
+	}
+
+	report, err := h.Service.RemoveMember(body.TeamName, body.UserID, actorFrom(r))
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			w.WriteHeader(http.StatusNotFound)
+			utils.WriteJSON(w, domain.ErrorResponse("NOT_FOUND", "team not found or user is not its member"))
+			return
+		}
+
+		log.Println(err)
+		w.WriteHeader(http.StatusInternalServerError)
+
+		// пользователь уже исключён из команды, часть ревью могла остаться за ним
+		if report != nil {
+			writePartialReassignment(w, "member removed, but reassignment stopped: reassign remaining PRs via /pullRequest/reassign",
+				map[string]any{"team_name": body.TeamName, "user_id": body.UserID}, report)
+			return
+		}
+
+		utils.WriteJSON(w, domain.ErrorResponse("INTERNAL_ERROR", "failed to remove team member"))
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
+	utils.WriteJSON(w, map[string]any{
+		"team_name":    body.TeamName,
+		"user_id":      body.UserID,
+		"reassignment": report,
+	})
+}
+
+// Rename handles POST /team/rename
+func (h *TeamHandler) Rename(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		utils.WriteJSON(w, domain.ErrorResponse("METHOD_NOT_ALLOWED", "method not allowed"))
+		return
+	}
+
+	var body struct {
+		TeamName    string `json:"team_name"`
+		NewTeamName string `json:"new_team_name"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		utils.WriteJSON(w, domain.ErrorResponse("INVALID_JSON", "invalid json body"))
+		return
+	}
+
+	if body.TeamName == "" || body.NewTeamName == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		utils.WriteJSON(w, domain.ErrorResponse("VALIDATION_ERROR", "team_name and new_team_name required"))
+		return
+	}
 
+	team, err := h.Service.Rename(body.TeamName, body.NewTeamName)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrNotFound):
+			w.WriteHeader(http.StatusNotFound)
+			utils.WriteJSON(w, domain.ErrorResponse("NOT_FOUND", "team not found"))
+		case errors.Is(err, domain.ErrTeamNameTaken):
+			w.WriteHeader(http.StatusBadRequest)
+			utils.WriteJSON(w, domain.ErrorResponse("TEAM_EXISTS", "new_team_name already exists"))
+		default:
+			log.Println(err)
+			w.WriteHeader(http.StatusInternalServerError)
+			utils.WriteJSON(w, domain.ErrorResponse("INTERNAL_ERROR", "failed to rename team"))
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
 	utils.WriteJSON(w, map[string]any{
 		"team": team,
 	})
 }
+
+// SetParent handles POST /team/setParent
+func (h *TeamHandler) SetParent(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		utils.WriteJSON(w, domain.ErrorResponse("METHOD_NOT_ALLOWED", "method not allowed"))
+		return
+	}
+
+	var body struct {
+		TeamName   string `json:"team_name"`
+		ParentTeam string `json:"parent_team"` // пусто — команда верхнего уровня
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		utils.WriteJSON(w, domain.ErrorResponse("INVALID_JSON", "invalid json body"))
+		return
+	}
+
+	if body.TeamName == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		utils.WriteJSON(w, domain.ErrorResponse("VALIDATION_ERROR", "team_name is required"))
+		return
+	}
+
+	team, err := h.Service.SetParent(body.TeamName, body.ParentTeam)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrNotFound):
+			w.WriteHeader(http.StatusNotFound)
+			utils.WriteJSON(w, domain.ErrorResponse("NOT_FOUND", "team or parent team not found"))
+		case errors.Is(err, domain.ErrInvalidParent):
+			w.WriteHeader(http.StatusBadRequest)
+			utils.WriteJSON(w, domain.ErrorResponse("INVALID_PARENT", "parent_team cannot be the team itself or one of its subteams"))
+		default:
+			log.Println(err)
+			w.WriteHeader(http.StatusInternalServerError)
+			utils.WriteJSON(w, domain.ErrorResponse("INTERNAL_ERROR", "failed to set parent team"))
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	utils.WriteJSON(w, map[string]any{
+		"team": team,
+	})
+}
+
+// Delete handles POST /team/delete
+func (h *TeamHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		utils.WriteJSON(w, domain.ErrorResponse("METHOD_NOT_ALLOWED", "method not allowed"))
+		return
+	}
+
+	var body struct {
+		TeamName string `json:"team_name"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		utils.WriteJSON(w, domain.ErrorResponse("INVALID_JSON", "invalid json body"))
+		return
+	}
+
+	if body.TeamName == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		utils.WriteJSON(w, domain.ErrorResponse("VALIDATION_ERROR", "team_name is required"))
+		return
+	}
+
+	if err := h.Service.Delete(body.TeamName); err != nil {
+		switch {
+		case errors.Is(err, domain.ErrNotFound):
+			w.WriteHeader(http.StatusNotFound)
+			utils.WriteJSON(w, domain.ErrorResponse("NOT_FOUND", "team not found"))
+		case errors.Is(err, domain.ErrTeamNotEmpty):
+			w.WriteHeader(http.StatusConflict)
+			utils.WriteJSON(w, domain.ErrorResponse("TEAM_NOT_EMPTY", "remove team members and move subteams first"))
+		default:
+			log.Println(err)
+			w.WriteHeader(http.StatusInternalServerError)
+			utils.WriteJSON(w, domain.ErrorResponse("INTERNAL_ERROR", "failed to delete team"))
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	utils.WriteJSON(w, map[string]any{
+		"deleted": body.TeamName,
+	})
+}
@@ -3,10 +3,12 @@ package handlers
 import (
 	"encoding/json"
 	"errors"
+	"log"
 	"net/http"
 	"pr-reviewer/internal/domain"
 	"pr-reviewer/internal/services"
 	"pr-reviewer/internal/utils"
+	"strings"
 )
 
 type UserHandler struct {
@@ -41,7 +43,7 @@ func (h *UserHandler) SetIsActive(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	userDTO, err := h.Service.SetIsActive(body.UserID, body.IsActive)
+	userDTO, report, err := h.Service.SetIsActive(body.UserID, body.IsActive, actorFrom(r))
 	if err != nil {
 
 		if errors.Is(err, domain.ErrNotFound) {
@@ -56,13 +58,342 @@ func (h *UserHandler) SetIsActive(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
+		log.Println(err)
 		w.WriteHeader(http.StatusInternalServerError)
+
+		// пользователь уже деактивирован, часть ревью переназначена — показываем, что осталось
+		if userDTO != nil {
+			writePartialReassignment(w, "user deactivated, but reassignment stopped: reassign pending PRs via /pullRequest/reassign",
+				map[string]any{"user": userDTO}, report)
+			return
+		}
+
 		utils.WriteJSON(w, domain.ErrorResponse("INTERNAL_ERROR", "failed to update user"))
 		return
 	}
 
+	resp := map[string]any{
+		"user": userDTO,
+	}
+	if report != nil {
+		resp["reassignment"] = report
+	}
+
+	w.WriteHeader(http.StatusOK)
+	utils.WriteJSON(w, resp)
+}
+
+// writePartialReassignment — ответ INTERNAL_ERROR, когда изменение уже применено, а переназначение
+// ревью прервалось: вместе с ошибкой отдаётся частичный отчёт (reassigned, no_candidate, pending)
+func writePartialReassignment(w http.ResponseWriter, msg string, resp map[string]any, report *domain.ReassignReport) {
+	resp["error"] = domain.ErrorResponse("INTERNAL_ERROR", msg).Error
+	if report != nil {
+		resp["reassignment"] = report
+	}
+	utils.WriteJSON(w, resp)
+}
+
+// SetMaxOpenReviews handles POST /users/setMaxOpenReviews
+func (h *UserHandler) SetMaxOpenReviews(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		utils.WriteJSON(w, domain.ErrorResponse("METHOD_NOT_ALLOWED", "method not allowed"))
+		return
+	}
+
+	var body struct {
+		UserID         string `json:"user_id"`
+		MaxOpenReviews *int   `json:"max_open_reviews"` // null — снять лимит
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		utils.WriteJSON(w, domain.ErrorResponse("INVALID_JSON", "invalid json body"))
+		return
+	}
+
+	if body.UserID == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		utils.WriteJSON(w, domain.ErrorResponse("VALIDATION_ERROR", "user_id is required"))
+		return
+	}
+
+	userDTO, err := h.Service.SetMaxOpenReviews(body.UserID, body.MaxOpenReviews)
+	if err != nil {
+
+		switch {
+		case errors.Is(err, domain.ErrNotFound):
+			w.WriteHeader(http.StatusNotFound)
+			utils.WriteJSON(w, domain.ErrorResponse("NOT_FOUND", "user not found"))
+		case errors.Is(err, domain.ErrInvalidCapacity):
+			w.WriteHeader(http.StatusBadRequest)
+			utils.WriteJSON(w, domain.ErrorResponse("VALIDATION_ERROR", "max_open_reviews must be non-negative or null"))
+		default:
+			log.Println(err)
+			w.WriteHeader(http.StatusInternalServerError)
+			utils.WriteJSON(w, domain.ErrorResponse("INTERNAL_ERROR", "failed to update user"))
+		}
+		return
+	}
+
 	w.WriteHeader(http.StatusOK)
 	utils.WriteJSON(w, map[string]any{
 		"user": userDTO,
 	})
 }
+
+// AddUnavailability handles POST /users/unavailability/add
+func (h *UserHandler) AddUnavailability(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		utils.WriteJSON(w, domain.ErrorResponse("METHOD_NOT_ALLOWED", "method not allowed"))
+		return
+	}
+
+	var period domain.Unavailability
+	if err := json.NewDecoder(r.Body).Decode(&period); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		utils.WriteJSON(w, domain.ErrorResponse("INVALID_JSON", "invalid json body"))
+		return
+	}
+
+	if period.UserID == "" || period.StartsAt.IsZero() || period.EndsAt.IsZero() {
+		w.WriteHeader(http.StatusBadRequest)
+		utils.WriteJSON(w, domain.ErrorResponse("VALIDATION_ERROR", "user_id, starts_at and ends_at required"))
+		return
+	}
+
+	if err := h.Service.AddUnavailability(&period); err != nil {
+
+		switch {
+		case errors.Is(err, domain.ErrNotFound):
+			w.WriteHeader(http.StatusNotFound)
+			utils.WriteJSON(w, domain.ErrorResponse("NOT_FOUND", "user not found"))
+		case errors.Is(err, domain.ErrInvalidPeriod):
+			w.WriteHeader(http.StatusBadRequest)
+			utils.WriteJSON(w, domain.ErrorResponse("VALIDATION_ERROR", "ends_at must be after starts_at"))
+		default:
+			log.Println(err)
+			w.WriteHeader(http.StatusInternalServerError)
+			utils.WriteJSON(w, domain.ErrorResponse("INTERNAL_ERROR", "failed to add unavailability"))
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	utils.WriteJSON(w, map[string]any{
+		"unavailability": period,
+	})
+}
+
+// ListUnavailability handles GET /users/unavailability/list
+func (h *UserHandler) ListUnavailability(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		utils.WriteJSON(w, domain.ErrorResponse("METHOD_NOT_ALLOWED", "method not allowed"))
+		return
+	}
+
+	userID := r.URL.Query().Get("user_id")
+	if userID == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		utils.WriteJSON(w, domain.ErrorResponse("VALIDATION_ERROR", "user_id is required"))
+		return
+	}
+
+	periods, err := h.Service.ListUnavailability(userID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			w.WriteHeader(http.StatusNotFound)
+			utils.WriteJSON(w, domain.ErrorResponse("NOT_FOUND", "user not found"))
+			return
+		}
+
+		log.Println(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		utils.WriteJSON(w, domain.ErrorResponse("INTERNAL_ERROR", "failed to list unavailability"))
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	utils.WriteJSON(w, map[string]any{
+		"user_id":        userID,
+		"unavailability": periods,
+	})
+}
+
+// DeleteUnavailability handles POST /users/unavailability/delete
+func (h *UserHandler) DeleteUnavailability(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		utils.WriteJSON(w, domain.ErrorResponse("METHOD_NOT_ALLOWED", "method not allowed"))
+		return
+	}
+
+	var body struct {
+		ID int64 `json:"id"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		utils.WriteJSON(w, domain.ErrorResponse("INVALID_JSON", "invalid json body"))
+		return
+	}
+
+	if body.ID == 0 {
+		w.WriteHeader(http.StatusBadRequest)
+		utils.WriteJSON(w, domain.ErrorResponse("VALIDATION_ERROR", "id is required"))
+		return
+	}
+
+	if err := h.Service.DeleteUnavailability(body.ID); err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			w.WriteHeader(http.StatusNotFound)
+			utils.WriteJSON(w, domain.ErrorResponse("NOT_FOUND", "unavailability not found"))
+			return
+		}
+
+		log.Println(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		utils.WriteJSON(w, domain.ErrorResponse("INTERNAL_ERROR", "failed to delete unavailability"))
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	utils.WriteJSON(w, map[string]any{
+		"deleted": body.ID,
+	})
+}
+
+// Get handles GET /users/get
+func (h *UserHandler) Get(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		utils.WriteJSON(w, domain.ErrorResponse("METHOD_NOT_ALLOWED", "method not allowed"))
+		return
+	}
+
+	userID := r.URL.Query().Get("user_id")
+	if userID == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		utils.WriteJSON(w, domain.ErrorResponse("VALIDATION_ERROR", "user_id is required"))
+		return
+	}
+
+	user, err := h.Service.Get(userID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			w.WriteHeader(http.StatusNotFound)
+			utils.WriteJSON(w, domain.ErrorResponse("NOT_FOUND", "user not found"))
+			return
+		}
+
+		log.Println(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		utils.WriteJSON(w, domain.ErrorResponse("INTERNAL_ERROR", "failed to get user"))
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	utils.WriteJSON(w, map[string]any{
+		"user": user,
+	})
+}
+
+// List handles GET /users/list
+func (h *UserHandler) List(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		utils.WriteJSON(w, domain.ErrorResponse("METHOD_NOT_ALLOWED", "method not allowed"))
+		return
+	}
+
+	q := r.URL.Query()
+
+	isActive, okActive := queryBool(q, "is_active")
+	limit, okLimit := queryInt(q, "limit")
+	if !okActive || !okLimit {
+		w.WriteHeader(http.StatusBadRequest)
+		utils.WriteJSON(w, domain.ErrorResponse("VALIDATION_ERROR", "is_active must be true or false, limit must be a number"))
+		return
+	}
+
+	filter := &domain.UserFilter{
+		TeamName:   q.Get("team_name"),
+		IsActive:   isActive,
+		NamePrefix: q.Get("name_prefix"),
+		Limit:      limit,
+		Cursor:     q.Get("cursor"),
+	}
+
+	page, err := h.Service.List(filter)
+	if err != nil {
+
+		switch {
+		case errors.Is(err, domain.ErrInvalidFilter):
+			w.WriteHeader(http.StatusBadRequest)
+			utils.WriteJSON(w, domain.ErrorResponse("VALIDATION_ERROR", "limit must be 1..100"))
+		case errors.Is(err, domain.ErrInvalidCursor):
+			w.WriteHeader(http.StatusBadRequest)
+			utils.WriteJSON(w, domain.ErrorResponse("INVALID_CURSOR", "invalid cursor"))
+		default:
+			log.Println(err)
+			w.WriteHeader(http.StatusInternalServerError)
+			utils.WriteJSON(w, domain.ErrorResponse("INTERNAL_ERROR", "failed to list users"))
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	utils.WriteJSON(w, page)
+}
+
+// Update handles POST /users/update
+func (h *UserHandler) Update(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		utils.WriteJSON(w, domain.ErrorResponse("METHOD_NOT_ALLOWED", "method not allowed"))
+		return
+	}
+
+	var body struct {
+		UserID   string `json:"user_id"`
+		Username string `json:"username"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		utils.WriteJSON(w, domain.ErrorResponse("INVALID_JSON", "invalid json body"))
+		return
+	}
+
+	if body.UserID == "" || strings.TrimSpace(body.Username) == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		utils.WriteJSON(w, domain.ErrorResponse("VALIDATION_ERROR", "user_id and username are required"))
+		return
+	}
+
+	user, err := h.Service.UpdateUsername(body.UserID, body.Username)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidUsername) {
+			w.WriteHeader(http.StatusBadRequest)
+			utils.WriteJSON(w, domain.ErrorResponse("VALIDATION_ERROR", "username must be 1..50 characters"))
+			return
+		}
+		if errors.Is(err, domain.ErrNotFound) {
+			w.WriteHeader(http.StatusNotFound)
+			utils.WriteJSON(w, domain.ErrorResponse("NOT_FOUND", "user not found"))
+			return
+		}
+
+		log.Println(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		utils.WriteJSON(w, domain.ErrorResponse("INTERNAL_ERROR", "failed to update user"))
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	utils.WriteJSON(w, map[string]any{
+		"user": user,
+	})
+}
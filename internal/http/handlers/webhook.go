@@ -0,0 +1,155 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"pr-reviewer/internal/domain"
+	"pr-reviewer/internal/services"
+	"pr-reviewer/internal/utils"
+	"strconv"
+)
+
+type WebhookHandler struct {
+	Service services.WebhookService
+}
+
+func NewWebhookHandler(s services.WebhookService) *WebhookHandler {
+	return &WebhookHandler{Service: s}
+}
+
+// Create handles POST /webhooks/add
+func (h *WebhookHandler) Create(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		utils.WriteJSON(w, domain.ErrorResponse("METHOD_NOT_ALLOWED", "method not allowed"))
+		return
+	}
+
+	var hook domain.Webhook
+	if err := json.NewDecoder(r.Body).Decode(&hook); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		utils.WriteJSON(w, domain.ErrorResponse("INVALID_JSON", "invalid json body"))
+		return
+	}
+
+	if err := h.Service.Create(&hook); err != nil {
+		if errors.Is(err, domain.ErrInvalidWebhook) {
+			w.WriteHeader(http.StatusBadRequest)
+			utils.WriteJSON(w, domain.ErrorResponse("VALIDATION_ERROR", "http(s) url, secret and known event types required"))
+			return
+		}
+
+		log.Println(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		utils.WriteJSON(w, domain.ErrorResponse("INTERNAL_ERROR", "failed to create webhook"))
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	utils.WriteJSON(w, map[string]any{
+		"webhook": hook,
+	})
+}
+
+// List handles GET /webhooks/list
+func (h *WebhookHandler) List(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		utils.WriteJSON(w, domain.ErrorResponse("METHOD_NOT_ALLOWED", "method not allowed"))
+		return
+	}
+
+	hooks, err := h.Service.List()
+	if err != nil {
+		log.Println(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		utils.WriteJSON(w, domain.ErrorResponse("INTERNAL_ERROR", "failed to list webhooks"))
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	utils.WriteJSON(w, map[string]any{
+		"webhooks": hooks,
+	})
+}
+
+// Delete handles POST /webhooks/delete
+func (h *WebhookHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		utils.WriteJSON(w, domain.ErrorResponse("METHOD_NOT_ALLOWED", "method not allowed"))
+		return
+	}
+
+	var body struct {
+		ID int64 `json:"webhook_id"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		utils.WriteJSON(w, domain.ErrorResponse("INVALID_JSON", "invalid json body"))
+		return
+	}
+
+	if body.ID == 0 {
+		w.WriteHeader(http.StatusBadRequest)
+		utils.WriteJSON(w, domain.ErrorResponse("VALIDATION_ERROR", "webhook_id is required"))
+		return
+	}
+
+	if err := h.Service.Delete(body.ID); err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			w.WriteHeader(http.StatusNotFound)
+			utils.WriteJSON(w, domain.ErrorResponse("NOT_FOUND", "webhook not found"))
+			return
+		}
+
+		log.Println(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		utils.WriteJSON(w, domain.ErrorResponse("INTERNAL_ERROR", "failed to delete webhook"))
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	utils.WriteJSON(w, map[string]any{
+		"deleted": body.ID,
+	})
+}
+
+// Deliveries handles GET /webhooks/deliveries
+func (h *WebhookHandler) Deliveries(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		utils.WriteJSON(w, domain.ErrorResponse("METHOD_NOT_ALLOWED", "method not allowed"))
+		return
+	}
+
+	webhookID, err := strconv.ParseInt(r.URL.Query().Get("webhook_id"), 10, 64)
+	if err != nil || webhookID <= 0 {
+		w.WriteHeader(http.StatusBadRequest)
+		utils.WriteJSON(w, domain.ErrorResponse("VALIDATION_ERROR", "webhook_id is required"))
+		return
+	}
+
+	deliveries, err := h.Service.Deliveries(webhookID, r.URL.Query().Get("status"))
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidWebhook) {
+			w.WriteHeader(http.StatusBadRequest)
+			utils.WriteJSON(w, domain.ErrorResponse("VALIDATION_ERROR", "status must be PENDING, SUCCESS or FAILED"))
+			return
+		}
+
+		log.Println(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		utils.WriteJSON(w, domain.ErrorResponse("INTERNAL_ERROR", "failed to list deliveries"))
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	utils.WriteJSON(w, map[string]any{
+		"webhook_id": webhookID,
+		"deliveries": deliveries,
+	})
+}
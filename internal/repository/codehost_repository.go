@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"pr-reviewer/internal/domain"
+
+	"github.com/lib/pq"
+)
+
+type CodeHostRepository interface {
+	SaveLink(link *domain.CodeHostPullRequest) error
+	GetLink(prID string) (*domain.CodeHostPullRequest, error)
+	Logins(provider string, userIDs []string) ([]string, error)
+}
+
+type codeHostRepository struct {
+	db *sql.DB
+}
+
+func NewCodeHostRepository(db *sql.DB) CodeHostRepository {
+	return &codeHostRepository{db: db}
+}
+
+func (r *codeHostRepository) SaveLink(link *domain.CodeHostPullRequest) error {
+	_, err := r.db.Exec(`
+        INSERT INTO code_host_pull_requests (pull_request_id, provider, repository, number)
+        VALUES ($1, $2, $3, $4)
+        ON CONFLICT (pull_request_id) DO UPDATE
+        SET provider = EXCLUDED.provider, repository = EXCLUDED.repository, number = EXCLUDED.number
+    `, link.PullRequestID, link.Provider, link.Repository, link.Number)
+	if err != nil {
+		return fmt.Errorf("upsert code_host_pull_requests: %w", err)
+	}
+	return nil
+}
+
+// GetLink — ErrNotFound, если PR создан не из code host
+func (r *codeHostRepository) GetLink(prID string) (*domain.CodeHostPullRequest, error) {
+	link := &domain.CodeHostPullRequest{PullRequestID: prID}
+	err := r.db.QueryRow(`
+        SELECT provider, repository, number FROM code_host_pull_requests WHERE pull_request_id=$1
+    `, prID).Scan(&link.Provider, &link.Repository, &link.Number)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, fmt.Errorf("select from code_host_pull_requests: %w", err)
+	}
+	return link, nil
+}
+
+// Logins — логины пользователей на code host; пользователи без связи пропускаются
+func (r *codeHostRepository) Logins(provider string, userIDs []string) ([]string, error) {
+	rows, err := r.db.Query(`
+        SELECT login FROM user_identities
+        WHERE provider=$1 AND user_id = ANY($2)
+        ORDER BY login
+    `, provider, pq.Array(userIDs))
+	if err != nil {
+		return nil, fmt.Errorf("select from user_identities: %w", err)
+	}
+	defer func() {
+		if cerr := rows.Close(); cerr != nil {
+			log.Println("rows close:", cerr)
+		}
+	}()
+
+	logins := []string{}
+	for rows.Next() {
+		var login string
+		if err := rows.Scan(&login); err != nil {
+			return nil, err
+		}
+		logins = append(logins, login)
+	}
+
+	return logins, rows.Err()
+}
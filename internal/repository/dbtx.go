@@ -0,0 +1,10 @@
+package repository
+
+import "database/sql"
+
+// dbtx — общее у *sql.DB и *sql.Tx, чтобы одни и те же запросы работали внутри транзакции
+type dbtx interface {
+	Exec(query string, args ...any) (sql.Result, error)
+	Query(query string, args ...any) (*sql.Rows, error)
+	QueryRow(query string, args ...any) *sql.Row
+}
@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"pr-reviewer/internal/domain"
+)
+
+type EventRepository interface {
+	ListByPullRequest(prID string) ([]domain.Event, error)
+}
+
+type eventRepository struct {
+	db *sql.DB
+}
+
+func NewEventRepository(db *sql.DB) EventRepository {
+	return &eventRepository{db: db}
+}
+
+// insertEvents пишет события в журнал и ставит их в outbox на рассылку.
+// Вызывается в транзакции изменения, к которому относятся события
+func insertEvents(q dbtx, events []domain.Event) error {
+	for i := range events {
+		e := &events[i]
+
+		payload := e.Payload
+		if payload == nil {
+			payload = []byte("{}")
+		}
+
+		err := q.QueryRow(`
+            INSERT INTO events (pull_request_id, user_id, actor, event_type, payload)
+            VALUES (NULLIF($1, ''), NULLIF($2, ''), NULLIF($3, ''), $4, $5)
+            RETURNING event_id, created_at
+        `, e.PullRequestID, e.UserID, e.Actor, e.Type, string(payload)).Scan(&e.ID, &e.CreatedAt)
+		if err != nil {
+			return fmt.Errorf("insert into events: %w", err)
+		}
+
+		if _, err := q.Exec(`INSERT INTO outbox (event_id) VALUES ($1)`, e.ID); err != nil {
+			return fmt.Errorf("insert into outbox: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// события PR в порядке записи
+func (r *eventRepository) ListByPullRequest(prID string) ([]domain.Event, error) {
+	rows, err := r.db.Query(`
+        SELECT event_id, pull_request_id, COALESCE(user_id, ''), COALESCE(actor, ''), event_type, payload, created_at
+        FROM events
+        WHERE pull_request_id = $1
+        ORDER BY created_at, event_id
+    `, prID)
+	if err != nil {
+		return nil, fmt.Errorf("select from events: %w", err)
+	}
+	defer func() {
+		if cerr := rows.Close(); cerr != nil {
+			log.Println("rows close:", cerr)
+		}
+	}()
+
+	events := []domain.Event{}
+	for rows.Next() {
+		var e domain.Event
+		var payload []byte
+		if err := rows.Scan(&e.ID, &e.PullRequestID, &e.UserID, &e.Actor, &e.Type, &payload, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		e.Payload = payload
+		events = append(events, e)
+	}
+
+	return events, rows.Err()
+}
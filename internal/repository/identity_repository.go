@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"pr-reviewer/internal/domain"
+)
+
+type IdentityRepository interface {
+	Upsert(identity *domain.Identity) error
+	Resolve(provider, login string) (string, error)
+	List(userID string) ([]domain.Identity, error)
+	Delete(provider, login string) error
+}
+
+type identityRepository struct {
+	db *sql.DB
+}
+
+func NewIdentityRepository(db *sql.DB) IdentityRepository {
+	return &identityRepository{db: db}
+}
+
+func (r *identityRepository) Upsert(identity *domain.Identity) error {
+	_, err := r.db.Exec(`
+        INSERT INTO user_identities (provider, login, user_id)
+        VALUES ($1, $2, $3)
+        ON CONFLICT (provider, login) DO UPDATE SET user_id = EXCLUDED.user_id
+    `, identity.Provider, identity.Login, identity.UserID)
+	if err != nil {
+		return fmt.Errorf("upsert user_identities: %w", err)
+	}
+	return nil
+}
+
+// Resolve возвращает user_id по логину; ErrNotFound, если связи нет
+func (r *identityRepository) Resolve(provider, login string) (string, error) {
+	var userID string
+	err := r.db.QueryRow(`
+        SELECT user_id FROM user_identities WHERE provider=$1 AND login=$2
+    `, provider, login).Scan(&userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", domain.ErrNotFound
+		}
+		return "", fmt.Errorf("select from user_identities: %w", err)
+	}
+	return userID, nil
+}
+
+// List — все связи или только пользователя userID
+func (r *identityRepository) List(userID string) ([]domain.Identity, error) {
+	rows, err := r.db.Query(`
+        SELECT provider, login, user_id
+        FROM user_identities
+        WHERE $1 = '' OR user_id = $1
+        ORDER BY provider, login
+    `, userID)
+	if err != nil {
+		return nil, fmt.Errorf("select from user_identities: %w", err)
+	}
+	defer func() {
+		if cerr := rows.Close(); cerr != nil {
+			log.Println("rows close:", cerr)
+		}
+	}()
+
+	identities := []domain.Identity{}
+	for rows.Next() {
+		var i domain.Identity
+		if err := rows.Scan(&i.Provider, &i.Login, &i.UserID); err != nil {
+			return nil, err
+		}
+		identities = append(identities, i)
+	}
+
+	return identities, rows.Err()
+}
+
+func (r *identityRepository) Delete(provider, login string) error {
+	res, err := r.db.Exec(`DELETE FROM user_identities WHERE provider=$1 AND login=$2`, provider, login)
+	if err != nil {
+		return fmt.Errorf("delete from user_identities: %w", err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return domain.ErrNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"pr-reviewer/internal/domain"
+	"time"
+)
+
+type OutboxRepository interface {
+	Pending(limit int) ([]domain.OutboxMessage, error)
+	MarkProcessed(id int64) error
+	MarkFailed(msg *domain.OutboxMessage, nextAttemptAt time.Time) error
+}
+
+type outboxRepository struct {
+	db *sql.DB
+}
+
+func NewOutboxRepository(db *sql.DB) OutboxRepository {
+	return &outboxRepository{db: db}
+}
+
+// Pending — необработанные сообщения, время повтора которых наступило, в порядке записи
+func (r *outboxRepository) Pending(limit int) ([]domain.OutboxMessage, error) {
+	rows, err := r.db.Query(`
+        SELECT o.outbox_id, o.attempts, o.last_error,
+               e.event_id, COALESCE(e.pull_request_id, ''), COALESCE(e.user_id, ''), COALESCE(e.actor, ''),
+               e.event_type, e.payload, e.created_at
+        FROM outbox o
+        JOIN events e ON e.event_id = o.event_id
+        WHERE o.processed_at IS NULL AND o.next_attempt_at <= NOW()
+        ORDER BY o.outbox_id
+        LIMIT $1
+    `, limit)
+	if err != nil {
+		return nil, fmt.Errorf("select from outbox: %w", err)
+	}
+	defer func() {
+		if cerr := rows.Close(); cerr != nil {
+			log.Println("rows close:", cerr)
+		}
+	}()
+
+	msgs := []domain.OutboxMessage{}
+	for rows.Next() {
+		var m domain.OutboxMessage
+		var payload []byte
+		err := rows.Scan(&m.ID, &m.Attempts, &m.LastError,
+			&m.Event.ID, &m.Event.PullRequestID, &m.Event.UserID, &m.Event.Actor,
+			&m.Event.Type, &payload, &m.Event.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		m.Event.Payload = payload
+		msgs = append(msgs, m)
+	}
+
+	return msgs, rows.Err()
+}
+
+func (r *outboxRepository) MarkProcessed(id int64) error {
+	_, err := r.db.Exec(`
+        UPDATE outbox
+        SET processed_at=NOW(), attempts=attempts+1, last_error=''
+        WHERE outbox_id=$1
+    `, id)
+	if err != nil {
+		return fmt.Errorf("update outbox: %w", err)
+	}
+	return nil
+}
+
+func (r *outboxRepository) MarkFailed(msg *domain.OutboxMessage, nextAttemptAt time.Time) error {
+	_, err := r.db.Exec(`
+        UPDATE outbox
+        SET attempts=$2, last_error=$3, next_attempt_at=$4
+        WHERE outbox_id=$1
+    `, msg.ID, msg.Attempts, msg.LastError, nextAttemptAt)
+	if err != nil {
+		return fmt.Errorf("update outbox: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"pr-reviewer/internal/domain"
+)
+
+type OwnershipRepository interface {
+	Create(rule *domain.OwnershipRule) error
+	List() ([]domain.OwnershipRule, error)
+	Delete(ruleID int64) error
+}
+
+type ownershipRepository struct {
+	db *sql.DB
+}
+
+func NewOwnershipRepository(db *sql.DB) OwnershipRepository {
+	return &ownershipRepository{db: db}
+}
+
+func (r *ownershipRepository) Create(rule *domain.OwnershipRule) error {
+	var userID *string
+	if rule.UserID != "" {
+		userID = &rule.UserID
+	}
+
+	var teamID *int64
+	if rule.TeamName != "" {
+		err := r.db.QueryRow("SELECT team_id FROM teams WHERE team_name=$1", rule.TeamName).Scan(&rule.TeamID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return domain.ErrNotFound
+			}
+			return fmt.Errorf("select from teams: %w", err)
+		}
+		teamID = &rule.TeamID
+	}
+
+	err := r.db.QueryRow(`
+        INSERT INTO ownership_rules (pattern, user_id, team_id)
+        VALUES ($1, $2, $3)
+        RETURNING rule_id
+    `, rule.Pattern, userID, teamID).Scan(&rule.ID)
+	if err != nil {
+		return fmt.Errorf("insert into ownership_rules: %w", err)
+	}
+
+	return nil
+}
+
+// правила в порядке добавления: как в CODEOWNERS, более позднее совпадение приоритетнее
+func (r *ownershipRepository) List() ([]domain.OwnershipRule, error) {
+	rows, err := r.db.Query(`
+        SELECT o.rule_id, o.pattern, COALESCE(o.user_id, ''), COALESCE(o.team_id, 0), COALESCE(t.team_name, '')
+        FROM ownership_rules o
+        LEFT JOIN teams t ON t.team_id = o.team_id
+        ORDER BY o.rule_id
+    `)
+	if err != nil {
+		return nil, fmt.Errorf("select from ownership_rules: %w", err)
+	}
+
+	defer func() {
+		if cerr := rows.Close(); cerr != nil {
+			log.Println("rows close:", cerr)
+		}
+	}()
+
+	rules := []domain.OwnershipRule{}
+	for rows.Next() {
+		var rule domain.OwnershipRule
+		if err := rows.Scan(&rule.ID, &rule.Pattern, &rule.UserID, &rule.TeamID, &rule.TeamName); err != nil {
+			return nil, fmt.Errorf("scan row: %w", err)
+		}
+		rules = append(rules, rule)
+	}
+
+	return rules, rows.Err()
+}
+
+func (r *ownershipRepository) Delete(ruleID int64) error {
+	res, err := r.db.Exec(`DELETE FROM ownership_rules WHERE rule_id = $1`, ruleID)
+	if err != nil {
+		return fmt.Errorf("delete from ownership_rules: %w", err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return domain.ErrNotFound
+	}
+
+	return nil
+}
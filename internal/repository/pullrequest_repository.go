@@ -3,31 +3,89 @@ package repository
 import (
 	"database/sql"
 	"errors"
+	"fmt"
 	"log"
 	"pr-reviewer/internal/domain"
 	"strings"
+	"time"
+
+	"github.com/lib/pq"
 )
 
 type PullRequestRepository interface {
+	WithTx(fn func(tx PullRequestRepository) error) error
+	AddEvents(events ...domain.Event) error
 	Exists(prID string) (bool, error)
 	Create(pr *domain.PullRequest) error
-	AssignReviewers(prID string, reviewers []string) error
+	AssignReviewers(prID string, reviewers []domain.Reviewer) error
 	GetTeamMembers(teamID int64, exclude string) ([]string, error)
+	FilterEligible(userIDs []string, exclude string) ([]string, error)
 	GetByID(prID string) (*domain.PullRequest, error)
-	Merge(prID string, timestamp string) error
+	Merge(prID string, timestamp string, force bool) error
+	SetStatus(prID, from, to string, closedAt *string) error
 	GetReviewers(prID string) ([]string, error)
-	ReplaceReviewer(prID, oldID, newID string) error
-	FindReplacement(teamID int64, authorID, oldReviewerID string, assigned []string) (string, error)
-	GetByReviewer(userID string) ([]domain.PullRequestShort, error)
+	ReplaceReviewer(prID, oldID, newID string, fallback bool) error
+	RemoveReviewer(prID, userID string) error
+	FindReplacement(teamID int64, authorID, oldReviewerID string, assigned []string) ([]string, error)
+	GetOpenReviewLoad(userIDs []string) (map[string]int, error)
+	CountAtCapacity(teamIDs []int64, exclude []string) (int, error)
+	GetByReviewer(filter *domain.ReviewFilter, after *domain.PageCursor) ([]domain.PullRequestShort, *domain.PageCursor, error)
+	GetOpenIDsByReviewer(userID string, teamID int64) ([]string, error)
+	List(filter *domain.PullRequestFilter, after *domain.PageCursor) ([]domain.PullRequestSummary, *domain.PageCursor, error)
 	GetReviewStats() ([]domain.ReviewerStat, error)
+	AddReview(review *domain.Review) error
 }
 
+// пользователь не в отпуске/отсутствии на текущий момент
+const availableNow = `NOT EXISTS (
+            SELECT 1 FROM user_unavailability a
+            WHERE a.user_id = users.user_id AND a.starts_at <= NOW() AND a.ends_at > NOW()
+        )`
+
+// у пользователя меньше OPEN ревью, чем max_open_reviews (NULL — без лимита)
+const hasCapacity = `(users.max_open_reviews IS NULL OR (
+            SELECT COUNT(*) FROM reviewers r
+            JOIN pull_requests p ON p.pull_request_id = r.pull_request_id
+            WHERE r.user_id = users.user_id AND p.status = 'OPEN'
+        ) < users.max_open_reviews)`
+
+// пользователь состоит в команде $1
+const inTeam = `user_id IN (SELECT user_id FROM team_memberships WHERE team_id = $1)`
+
 type pullRequestRepository struct {
-	db *sql.DB
+	db   dbtx
+	conn *sql.DB // nil у репозитория внутри транзакции
 }
 
 func NewPullRequestRepository(db *sql.DB) PullRequestRepository {
-	return &pullRequestRepository{db: db}
+	return &pullRequestRepository{db: db, conn: db}
+}
+
+// WithTx выполняет fn в одной транзакции: изменения PR, ревьюверов и события (AddEvents)
+// фиксируются вместе. Вложенный вызов выполняется в уже открытой транзакции
+func (r *pullRequestRepository) WithTx(fn func(tx PullRequestRepository) error) error {
+	if r.conn == nil {
+		return fn(r)
+	}
+
+	tx, err := r.conn.Begin()
+	if err != nil {
+		return errors.New("tx begin: " + err.Error())
+	}
+
+	if err := fn(&pullRequestRepository{db: tx}); err != nil {
+		if err := tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+			log.Println("rollback:", err)
+		}
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// AddEvents пишет события в журнал и outbox; вызывать внутри WithTx
+func (r *pullRequestRepository) AddEvents(events ...domain.Event) error {
+	return insertEvents(r.db, events)
 }
 
 func (r *pullRequestRepository) Exists(prID string) (bool, error) {
@@ -38,15 +96,18 @@ func (r *pullRequestRepository) Exists(prID string) (bool, error) {
 
 func (r *pullRequestRepository) Create(pr *domain.PullRequest) error {
 	_, err := r.db.Exec(`
-        INSERT INTO pull_requests (pull_request_id, title, author, status)
-        VALUES ($1, $2, $3, $4)
-    `, pr.ID, pr.Name, pr.AuthorID, pr.Status)
+        INSERT INTO pull_requests (pull_request_id, title, author, status, changed_files, team_id)
+        VALUES ($1, $2, $3, $4, COALESCE($5, '{}'::TEXT[]), NULLIF($6, 0))
+    `, pr.ID, pr.Name, pr.AuthorID, pr.Status, pq.Array(pr.ChangedFiles), pr.TeamID)
 	return err
 }
 
-func (r *pullRequestRepository) AssignReviewers(prID string, reviewers []string) error {
-	for _, uid := range reviewers {
-		_, err := r.db.Exec(`INSERT INTO reviewers (pull_request_id, user_id) VALUES ($1, $2)`, prID, uid)
+func (r *pullRequestRepository) AssignReviewers(prID string, reviewers []domain.Reviewer) error {
+	for _, rv := range reviewers {
+		_, err := r.db.Exec(`
+            INSERT INTO reviewers (pull_request_id, user_id, is_fallback, owner_rule)
+            VALUES ($1, $2, $3, NULLIF($4, ''))
+        `, prID, rv.UserID, rv.Fallback, rv.OwnerRule)
 		if err != nil {
 			return err
 		}
@@ -54,46 +115,51 @@ func (r *pullRequestRepository) AssignReviewers(prID string, reviewers []string)
 	return nil
 }
 
-// до 2 участников команды, исключая автора
-func (r *pullRequestRepository) GetTeamMembers(teamID int64, exclude string) ([]string, error) {
+// оставляет из userIDs тех, кого сейчас можно назначить ревьювером
+func (r *pullRequestRepository) FilterEligible(userIDs []string, exclude string) ([]string, error) {
 	rows, err := r.db.Query(`
         SELECT user_id FROM users
-        WHERE team_id=$1 AND is_active=true AND user_id != $2
-        LIMIT 2
-    `, teamID, exclude)
+        WHERE user_id = ANY($1) AND is_active=true AND user_id != $2
+        AND `+availableNow+` AND `+hasCapacity+`
+        ORDER BY user_id
+    `, pq.Array(userIDs), exclude)
 	if err != nil {
 		return nil, err
 	}
 
-	defer func() {
-		if cerr := rows.Close(); cerr != nil {
-			log.Println("rows close:", cerr)
-		}
-	}()
+	return scanIDs(rows)
+}
 
-	var ids []string
-	for rows.Next() {
-		var id string
-		if err := rows.Scan(&id); err != nil {
-			return nil, err
-		}
-		ids = append(ids, id)
+// все активные участники команды (в том числе из других основных команд), исключая автора;
+// выбор делает ReviewerSelector
+func (r *pullRequestRepository) GetTeamMembers(teamID int64, exclude string) ([]string, error) {
+	rows, err := r.db.Query(`
+        SELECT user_id FROM users
+        WHERE `+inTeam+` AND is_active=true AND user_id != $2
+        AND `+availableNow+` AND `+hasCapacity+`
+        ORDER BY user_id
+    `, teamID, exclude)
+	if err != nil {
+		return nil, err
 	}
 
-	return ids, nil
+	return scanIDs(rows)
 }
 
 func (r *pullRequestRepository) GetByID(prID string) (*domain.PullRequest, error) {
 	row := r.db.QueryRow(`
-        SELECT pull_request_id, title, author, status, created_at, merged_at
-        FROM pull_requests
-        WHERE pull_request_id=$1
+        SELECT pr.pull_request_id, pr.title, pr.author, pr.status, pr.created_at, pr.merged_at, pr.closed_at, pr.force_merged, pr.changed_files,
+            COALESCE(pr.team_id, 0), COALESCE(t.team_name, ''), COALESCE(pr.closed_from, '')
+        FROM pull_requests pr
+        LEFT JOIN teams t ON t.team_id = pr.team_id
+        WHERE pr.pull_request_id=$1
     `, prID)
 
 	pr := &domain.PullRequest{}
 	var mergedAt *string
 
-	err := row.Scan(&pr.ID, &pr.Name, &pr.AuthorID, &pr.Status, &pr.CreatedAt, &mergedAt)
+	err := row.Scan(&pr.ID, &pr.Name, &pr.AuthorID, &pr.Status, &pr.CreatedAt, &mergedAt, &pr.ClosedAt, &pr.ForceMerged, pq.Array(&pr.ChangedFiles),
+		&pr.TeamID, &pr.TeamName, &pr.ClosedFrom)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, domain.ErrNotFound
@@ -103,7 +169,7 @@ func (r *pullRequestRepository) GetByID(prID string) (*domain.PullRequest, error
 
 	pr.MergedAt = mergedAt
 
-	reviewers, err := r.GetReviewers(prID)
+	reviewers, err := r.getReviewerStates(prID)
 	if err != nil {
 		return nil, err
 	}
@@ -112,88 +178,229 @@ func (r *pullRequestRepository) GetByID(prID string) (*domain.PullRequest, error
 	return pr, nil
 }
 
-func (r *pullRequestRepository) Merge(prID string, timestamp string) error {
-	_, err := r.db.Exec(`
-        UPDATE pull_requests
-        SET status='MERGED', merged_at=$2
-        WHERE pull_request_id=$1
-    `, prID, timestamp)
-	return err
+// ревьюверы PR с последним вердиктом каждого
+func (r *pullRequestRepository) getReviewerStates(prID string) ([]domain.Reviewer, error) {
+	states, err := r.getReviewerStatesBatch([]string{prID})
+	if err != nil {
+		return nil, err
+	}
+
+	if states[prID] == nil {
+		return []domain.Reviewer{}, nil
+	}
+	return states[prID], nil
 }
 
-func (r *pullRequestRepository) GetReviewers(prID string) ([]string, error) {
-	rows, err := r.db.Query(`SELECT user_id FROM reviewers WHERE pull_request_id=$1`, prID)
+// getReviewerStatesBatch — ревьюверы нескольких PR одним запросом: pull_request_id -> ревьюверы
+func (r *pullRequestRepository) getReviewerStatesBatch(prIDs []string) (map[string][]domain.Reviewer, error) {
+	rows, err := r.db.Query(`
+        SELECT r.pull_request_id, r.user_id, r.is_fallback, COALESCE(r.owner_rule, ''), v.verdict, v.created_at
+        FROM reviewers r
+        LEFT JOIN LATERAL (
+            SELECT verdict, created_at
+            FROM reviews
+            WHERE pull_request_id = r.pull_request_id AND user_id = r.user_id
+            ORDER BY created_at DESC, review_id DESC
+            LIMIT 1
+        ) v ON true
+        WHERE r.pull_request_id = ANY($1)
+        ORDER BY r.pull_request_id, r.user_id
+    `, pq.Array(prIDs))
 	if err != nil {
 		return nil, err
 	}
-
 	defer func() {
 		if cerr := rows.Close(); cerr != nil {
 			log.Println("rows close:", cerr)
 		}
 	}()
 
-	var reviewers []string
+	states := make(map[string][]domain.Reviewer, len(prIDs))
 	for rows.Next() {
-		var id string
-		if err := rows.Scan(&id); err != nil {
+		var prID string
+		var rv domain.Reviewer
+		var verdict sql.NullString
+		if err := rows.Scan(&prID, &rv.UserID, &rv.Fallback, &rv.OwnerRule, &verdict, &rv.VerdictAt); err != nil {
 			return nil, err
 		}
-		reviewers = append(reviewers, id)
+		rv.Verdict = verdict.String
+		states[prID] = append(states[prID], rv)
 	}
-	return reviewers, nil
+
+	return states, rows.Err()
 }
 
-func (r *pullRequestRepository) FindReplacement(teamID int64, authorID, oldReviewerID string, assigned []string) (string, error) {
+func (r *pullRequestRepository) AddReview(review *domain.Review) error {
+	return r.db.QueryRow(`
+        INSERT INTO reviews (pull_request_id, user_id, verdict, comment)
+        VALUES ($1, $2, $3, $4)
+        RETURNING review_id, created_at
+    `, review.PullRequestID, review.ReviewerID, review.Verdict, review.Comment).Scan(&review.ID, &review.CreatedAt)
+}
+
+// Merge только из OPEN; если статус успели изменить — ErrConcurrentUpdate
+func (r *pullRequestRepository) Merge(prID string, timestamp string, force bool) error {
+	res, err := r.db.Exec(`
+        UPDATE pull_requests
+        SET status='MERGED', merged_at=$2, force_merged=$3
+        WHERE pull_request_id=$1 AND status='OPEN'
+    `, prID, timestamp, force)
+	if err != nil {
+		return err
+	}
+	return checkStatusUpdated(res)
+}
+
+// SetStatus для переходов DRAFT/OPEN/CLOSED из статуса from; closed_at пишется только при закрытии,
+// closed_from — статус, из которого закрыли. Если статус уже не from — ErrConcurrentUpdate
+func (r *pullRequestRepository) SetStatus(prID, from, to string, closedAt *string) error {
+	res, err := r.db.Exec(`
+        UPDATE pull_requests
+        SET status=$3, closed_at=$4,
+        closed_from = CASE WHEN $3 = 'CLOSED' THEN status END
+        WHERE pull_request_id=$1 AND status=$2
+    `, prID, from, to, closedAt)
+	if err != nil {
+		return err
+	}
+	return checkStatusUpdated(res)
+}
+
+func checkStatusUpdated(res sql.Result) error {
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return domain.ErrConcurrentUpdate
+	}
+	return nil
+}
+
+func (r *pullRequestRepository) GetReviewers(prID string) ([]string, error) {
+	rows, err := r.db.Query(`SELECT user_id FROM reviewers WHERE pull_request_id=$1`, prID)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanIDs(rows)
+}
+
+func (r *pullRequestRepository) FindReplacement(teamID int64, authorID, oldReviewerID string, assigned []string) ([]string, error) {
 
 	// исключаем: автора, старого ревьювера, уже назначенных
-	query := `
+	rows, err := r.db.Query(`
         SELECT user_id
         FROM users
-        WHERE team_id = $1
+        WHERE `+inTeam+`
         AND is_active = true
         AND user_id != $2
         AND user_id != $3
-        AND user_id NOT IN ($4)
-        ORDER BY random()
-        LIMIT 1;
-    `
+        AND NOT (user_id = ANY($4))
+        AND `+availableNow+` AND `+hasCapacity+`
+        ORDER BY user_id
+    `, teamID, authorID, oldReviewerID, pq.Array(assigned))
+	if err != nil {
+		return nil, err
+	}
+
+	candidates, err := scanIDs(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(candidates) == 0 {
+		return nil, domain.ErrNoCandidate
+	}
 
-	assignedArray := "{" + strings.Join(assigned, ",") + "}"
+	return candidates, nil
+}
+
+// активные и доступные участники команд, которых не назначили только из-за лимита ревью
+func (r *pullRequestRepository) CountAtCapacity(teamIDs []int64, exclude []string) (int, error) {
+	var cnt int
+	err := r.db.QueryRow(`
+        SELECT COUNT(*)
+        FROM users
+        WHERE user_id IN (SELECT user_id FROM team_memberships WHERE team_id = ANY($1))
+        AND is_active = true
+        AND NOT (user_id = ANY($2))
+        AND `+availableNow+`
+        AND NOT `+hasCapacity, pq.Array(teamIDs), pq.Array(exclude)).Scan(&cnt)
+	return cnt, err
+}
 
-	var candidate string
-	err := r.db.QueryRow(query, teamID, authorID, oldReviewerID, assignedArray).Scan(&candidate)
+// количество OPEN pull request'ов, на которые назначен каждый пользователь
+func (r *pullRequestRepository) GetOpenReviewLoad(userIDs []string) (map[string]int, error) {
+	rows, err := r.db.Query(`
+        SELECT r.user_id, COUNT(*)
+        FROM reviewers r
+        JOIN pull_requests pr ON pr.pull_request_id = r.pull_request_id
+        WHERE r.user_id = ANY($1) AND pr.status = 'OPEN'
+        GROUP BY r.user_id
+    `, pq.Array(userIDs))
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return "", domain.ErrNoCandidate
+		return nil, err
+	}
+	defer func() {
+		if cerr := rows.Close(); cerr != nil {
+			log.Println("rows close:", cerr)
 		}
-		return "", err
+	}()
+
+	load := make(map[string]int, len(userIDs))
+	for rows.Next() {
+		var id string
+		var cnt int
+		if err := rows.Scan(&id, &cnt); err != nil {
+			return nil, err
+		}
+		load[id] = cnt
 	}
 
-	return candidate, nil
+	return load, rows.Err()
 }
 
-func (r *pullRequestRepository) ReplaceReviewer(prID, oldID, newID string) error {
+func (r *pullRequestRepository) ReplaceReviewer(prID, oldID, newID string, fallback bool) error {
 	_, err := r.db.Exec(`
         UPDATE reviewers
-        SET user_id = $1
+        SET user_id = $1, is_fallback = $4, owner_rule = NULL
         WHERE pull_request_id = $2 AND user_id = $3
-    `, newID, prID, oldID)
+    `, newID, prID, oldID, fallback)
 
 	return err
 }
 
-func (r *pullRequestRepository) GetByReviewer(userID string) ([]domain.PullRequestShort, error) {
+func (r *pullRequestRepository) RemoveReviewer(prID, userID string) error {
+	_, err := r.db.Exec(`DELETE FROM reviewers WHERE pull_request_id = $1 AND user_id = $2`, prID, userID)
+	return err
+}
 
-	rows, err := r.db.Query(`
-        SELECT pr.pull_request_id, pr.title, pr.author, pr.status
+// GetByReviewer возвращает страницу PR ревьювера и курсор следующей (nil — страниц больше нет)
+func (r *pullRequestRepository) GetByReviewer(filter *domain.ReviewFilter, after *domain.PageCursor) ([]domain.PullRequestShort, *domain.PageCursor, error) {
+	order, cmp := pageOrder(filter.Sort)
+
+	var afterAt *time.Time
+	var afterID string
+	if after != nil {
+		afterAt, afterID = &after.CreatedAt, after.ID
+	}
+
+	rows, err := r.db.Query(fmt.Sprintf(`
+        SELECT pr.pull_request_id, pr.title, pr.author, pr.status, pr.created_at
         FROM pull_requests pr
         JOIN reviewers r ON pr.pull_request_id = r.pull_request_id
         WHERE r.user_id = $1
-    `, userID)
+          AND (cardinality($2::text[]) = 0 OR pr.status = ANY($2))
+          AND ($3::timestamp IS NULL OR pr.created_at >= $3)
+          AND ($4::timestamp IS NULL OR pr.created_at < $4)
+          AND ($5::timestamp IS NULL OR (pr.created_at, pr.pull_request_id) %s ($5, $6))
+        ORDER BY %s
+        LIMIT $7
+    `, cmp, order), filter.UserID, pq.Array(filter.Statuses), filter.CreatedAfter, filter.CreatedBefore, afterAt, afterID, filter.Limit+1)
 
 	if err != nil {
-		return nil, err
+		return nil, nil, fmt.Errorf("select reviewer pull requests: %w", err)
 	}
 	defer func() {
 		if cerr := rows.Close(); cerr != nil {
@@ -201,30 +408,159 @@ func (r *pullRequestRepository) GetByReviewer(userID string) ([]domain.PullReque
 		}
 	}()
 
-	var prs []domain.PullRequestShort
+	prs := []domain.PullRequestShort{}
+	var last domain.PageCursor
 
 	for rows.Next() {
 		var pr domain.PullRequestShort
-		err := rows.Scan(&pr.ID, &pr.Name, &pr.AuthorID, &pr.Status)
+		var createdAt time.Time
+		err := rows.Scan(&pr.ID, &pr.Name, &pr.AuthorID, &pr.Status, &createdAt)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
+
+		// лишняя строка только сообщает, что есть следующая страница
+		if len(prs) == filter.Limit {
+			return prs, &last, rows.Err()
+		}
+
+		pr.CreatedAt = createdAt.Format(time.RFC3339Nano)
 		prs = append(prs, pr)
+		last = domain.PageCursor{CreatedAt: createdAt, ID: pr.ID}
 	}
 
-	if len(prs) == 0 {
-		return nil, domain.ErrNotFound
+	return prs, nil, rows.Err()
+}
+
+// List — страница PR по фильтру вместе с ревьюверами (вторым запросом на всю страницу)
+func (r *pullRequestRepository) List(filter *domain.PullRequestFilter, after *domain.PageCursor) ([]domain.PullRequestSummary, *domain.PageCursor, error) {
+	order, cmp := pageOrder(filter.Sort)
+
+	var afterAt *time.Time
+	var afterID string
+	if after != nil {
+		afterAt, afterID = &after.CreatedAt, after.ID
 	}
 
-	return prs, nil
+	rows, err := r.db.Query(fmt.Sprintf(`
+        SELECT pr.pull_request_id, pr.title, pr.author, pr.status, pr.created_at, pr.merged_at
+        FROM pull_requests pr
+        WHERE ($1 = '' OR pr.author = $1)
+          AND ($2 = '' OR pr.team_id = (SELECT team_id FROM teams WHERE team_name = $2))
+          AND ($3 = '' OR EXISTS (
+              SELECT 1 FROM reviewers rv WHERE rv.pull_request_id = pr.pull_request_id AND rv.user_id = $3
+          ))
+          AND (cardinality($4::text[]) = 0 OR pr.status = ANY($4))
+          AND ($5 = '' OR pr.title ILIKE '%%' || $5 || '%%')
+          AND ($6::timestamp IS NULL OR pr.created_at >= $6)
+          AND ($7::timestamp IS NULL OR pr.created_at < $7)
+          AND ($8::timestamp IS NULL OR pr.merged_at >= $8)
+          AND ($9::timestamp IS NULL OR pr.merged_at < $9)
+          AND ($10::timestamp IS NULL OR (pr.created_at, pr.pull_request_id) %s ($10, $11))
+        ORDER BY %s
+        LIMIT $12
+    `, cmp, order),
+		filter.AuthorID, filter.TeamName, filter.ReviewerID, pq.Array(filter.Statuses), escapeLike(filter.Title),
+		filter.CreatedAfter, filter.CreatedBefore, filter.MergedAfter, filter.MergedBefore,
+		afterAt, afterID, filter.Limit+1)
+
+	if err != nil {
+		return nil, nil, fmt.Errorf("select pull requests: %w", err)
+	}
+	defer func() {
+		if cerr := rows.Close(); cerr != nil {
+			log.Println("rows close:", cerr)
+		}
+	}()
+
+	prs := []domain.PullRequestSummary{}
+	var last domain.PageCursor
+	var next *domain.PageCursor
+
+	for rows.Next() {
+		var pr domain.PullRequestSummary
+		var createdAt time.Time
+		err := rows.Scan(&pr.ID, &pr.Name, &pr.AuthorID, &pr.Status, &createdAt, &pr.MergedAt)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		// лишняя строка только сообщает, что есть следующая страница
+		if len(prs) == filter.Limit {
+			next = &last
+			break
+		}
+
+		pr.CreatedAt = createdAt.Format(time.RFC3339Nano)
+		prs = append(prs, pr)
+		last = domain.PageCursor{CreatedAt: createdAt, ID: pr.ID}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	ids := make([]string, 0, len(prs))
+	for _, pr := range prs {
+		ids = append(ids, pr.ID)
+	}
+
+	states, err := r.getReviewerStatesBatch(ids)
+	if err != nil {
+		return nil, nil, err
+	}
+	for i := range prs {
+		prs[i].AssignedReviewers = states[prs[i].ID]
+		if prs[i].AssignedReviewers == nil {
+			prs[i].AssignedReviewers = []domain.Reviewer{}
+		}
+	}
+
+	return prs, next, nil
+}
+
+// escapeLike экранирует % и _ для поиска подстроки через LIKE
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
 }
 
+// pageOrder — ORDER BY и сравнение с курсором для сортировки по (created_at, pull_request_id)
+func pageOrder(sort string) (string, string) {
+	if sort == domain.SortCreatedAsc {
+		return "pr.created_at, pr.pull_request_id", ">"
+	}
+	return "pr.created_at DESC, pr.pull_request_id DESC", "<"
+}
+
+// OPEN PR, где пользователь ревьювер; teamID != 0 — только PR этой команды
+func (r *pullRequestRepository) GetOpenIDsByReviewer(userID string, teamID int64) ([]string, error) {
+	rows, err := r.db.Query(`
+        SELECT pr.pull_request_id
+        FROM pull_requests pr
+        JOIN reviewers r ON pr.pull_request_id = r.pull_request_id
+        WHERE r.user_id = $1 AND pr.status = 'OPEN'
+        AND ($2 = 0 OR pr.team_id = $2)
+        ORDER BY pr.created_at, pr.pull_request_id
+    `, userID, teamID)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanIDs(rows)
+}
+
+// GetReviewStats: активные пользователи без назначений тоже попадают в статистику (с нулями),
+// неактивные — только если у них были ревью
 func (r *pullRequestRepository) GetReviewStats() ([]domain.ReviewerStat, error) {
 	rows, err := r.db.Query(`
-        SELECT user_id, COUNT(*) as cnt
-        FROM reviewers
-        GROUP BY user_id
-        ORDER BY cnt DESC
+        SELECT u.user_id,
+               COUNT(pr.pull_request_id) AS cnt,
+               COUNT(pr.pull_request_id) FILTER (WHERE pr.status = 'OPEN') AS open_cnt
+        FROM users u
+        LEFT JOIN reviewers r ON r.user_id = u.user_id
+        LEFT JOIN pull_requests pr ON pr.pull_request_id = r.pull_request_id
+        WHERE u.is_active OR r.user_id IS NOT NULL
+        GROUP BY u.user_id
+        ORDER BY cnt DESC, u.user_id
     `)
 	if err != nil {
 		return nil, err
@@ -238,12 +574,31 @@ func (r *pullRequestRepository) GetReviewStats() ([]domain.ReviewerStat, error)
 	var stats []domain.ReviewerStat
 	for rows.Next() {
 		var s domain.ReviewerStat
-		err := rows.Scan(&s.UserID, &s.Count)
+		err := rows.Scan(&s.UserID, &s.Count, &s.OpenCount)
 		if err != nil {
 			return nil, err
 		}
 		stats = append(stats, s)
 	}
 
-	return stats, nil
+	return stats, rows.Err()
+}
+
+func scanIDs(rows *sql.Rows) ([]string, error) {
+	defer func() {
+		if cerr := rows.Close(); cerr != nil {
+			log.Println("rows close:", cerr)
+		}
+	}()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
 }
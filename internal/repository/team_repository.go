@@ -12,8 +12,23 @@ type TeamRepository interface {
 	Create(team *domain.Team) error
 	Get(team_name string) (*domain.Team, error)
 	Exist(team_name string) (bool, error)
+	GetSettings(teamID int64) (*domain.TeamSettings, error)
+	UpdateSettings(teamID int64, settings *domain.TeamSettings) error
+	GetFallbackTeamIDs(teamID int64) ([]int64, error)
+	AddMember(teamID int64, user *domain.User, primary bool, events ...domain.Event) error
+	RemoveMember(teamID int64, userID string, events ...domain.Event) error
+	Rename(teamID int64, teamName string) error
+	Delete(teamID int64) error
+	SetParent(teamID, parentID int64) error
+	Subteams(teamID int64) ([]domain.Team, error)
+	GetSiblingIDs(teamID int64) ([]int64, error)
+	GetAncestorIDs(teamID int64) ([]int64, error)
+	GetLeadIDs(teamID int64) ([]string, error)
 }
 
+// ограничение глубины рекурсивных запросов по иерархии команд
+const maxTeamDepth = 16
+
 type teamRepository struct {
 	db *sql.DB
 }
@@ -30,8 +45,8 @@ func (r *teamRepository) Create(team *domain.Team) error {
 	}
 
 	err = tx.QueryRow(
-		"INSERT INTO teams(team_name) VALUES ($1) RETURNING team_id",
-		team.TeamName,
+		"INSERT INTO teams(team_name, parent_id) VALUES ($1, NULLIF($2, 0)) RETURNING team_id",
+		team.TeamName, team.ParentID,
 	).Scan(&team.ID)
 
 	if err != nil {
@@ -42,14 +57,20 @@ func (r *teamRepository) Create(team *domain.Team) error {
 		return errors.New("insert into teams: " + err.Error())
 	}
 
+	// участник другой команды остаётся в ней и сохраняет основную команду;
+	// без max_open_reviews в запросе прежний лимит не сбрасывается
 	for _, user := range team.Members {
 		_, err = tx.Exec(`
-        INSERT INTO users(user_id, username, is_active, team_id)
-        VALUES ($1, $2, $3, $4)
+        INSERT INTO users(user_id, username, is_active, team_id, max_open_reviews)
+        VALUES ($1, $2, $3, $4, $5)
         ON CONFLICT (user_id) DO UPDATE
         SET username = EXCLUDED.username,
         is_active = EXCLUDED.is_active,
-        team_id = EXCLUDED.team_id;`, user.ID, user.UserName, user.IsActive, team.ID)
+        team_id = COALESCE(users.team_id, EXCLUDED.team_id),
+        max_open_reviews = COALESCE(EXCLUDED.max_open_reviews, users.max_open_reviews);`, user.ID, user.UserName, user.IsActive, team.ID, user.MaxOpenReviews)
+		if err == nil {
+			err = addMembership(tx, team.ID, user.ID, user.Role)
+		}
 
 		if err != nil {
 			if err := tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
@@ -61,6 +82,16 @@ func (r *teamRepository) Create(team *domain.Team) error {
 
 	}
 
+	if team.Settings != nil {
+		if err := saveSettings(tx, team.ID, team.Settings); err != nil {
+			if err := tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+				log.Println("rollback:", err)
+			}
+
+			return errors.New("insert into team_settings: " + err.Error())
+		}
+	}
+
 	if err := tx.Commit(); err != nil {
 		return err
 	}
@@ -71,9 +102,15 @@ func (r *teamRepository) Create(team *domain.Team) error {
 
 func (r *teamRepository) Get(team_name string) (*domain.Team, error) {
 
-	var team_id int64
+	var team_id, parent_id int64
+	var parent_name string
 
-	err := r.db.QueryRow("SELECT team_id FROM teams WHERE team_name=$1", team_name).Scan(&team_id)
+	err := r.db.QueryRow(`
+        SELECT t.team_id, COALESCE(t.parent_id, 0), COALESCE(p.team_name, '')
+        FROM teams t
+        LEFT JOIN teams p ON p.team_id = t.parent_id
+        WHERE t.team_name=$1
+    `, team_name).Scan(&team_id, &parent_id, &parent_name)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, domain.ErrNotFound
@@ -81,7 +118,35 @@ func (r *teamRepository) Get(team_name string) (*domain.Team, error) {
 		return nil, fmt.Errorf("select from teams: %w", err)
 	}
 
-	rows, err := r.db.Query("SELECT user_id, username, is_active, team_id FROM users WHERE team_id=$1", team_id)
+	users, err := r.members(team_id)
+	if err != nil {
+		return nil, err
+	}
+
+	settings, err := r.GetSettings(team_id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.Team{
+		ID:         team_id,
+		TeamName:   team_name,
+		ParentID:   parent_id,
+		ParentTeam: parent_name,
+		Members:    users,
+		Settings:   settings,
+	}, nil
+
+}
+
+func (r *teamRepository) members(team_id int64) ([]domain.User, error) {
+	rows, err := r.db.Query(`
+        SELECT u.user_id, u.username, u.is_active, COALESCE(u.team_id, 0), u.max_open_reviews, m.role
+        FROM users u
+        JOIN team_memberships m ON m.user_id = u.user_id
+        WHERE m.team_id = $1
+        ORDER BY u.user_id
+    `, team_id)
 
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -101,7 +166,7 @@ func (r *teamRepository) Get(team_name string) (*domain.Team, error) {
 	for rows.Next() {
 		var user domain.User
 
-		err := rows.Scan(&user.ID, &user.UserName, &user.IsActive, &user.TeamID)
+		err := rows.Scan(&user.ID, &user.UserName, &user.IsActive, &user.TeamID, &user.MaxOpenReviews, &user.Role)
 
 		if err != nil {
 			return nil, errors.New("scan row: " + err.Error())
@@ -109,12 +174,120 @@ func (r *teamRepository) Get(team_name string) (*domain.Team, error) {
 		users = append(users, user)
 	}
 
-	return &domain.Team{
-		ID:       team_id,
-		TeamName: team_name,
-		Members:  users,
-	}, nil
+	return users, rows.Err()
+}
+
+// Subteams — все команды поддерева (без самой команды) с участниками, без настроек;
+// порядок: по глубине, затем по имени
+func (r *teamRepository) Subteams(teamID int64) ([]domain.Team, error) {
+	rows, err := r.db.Query(`
+        WITH RECURSIVE tree AS (
+            SELECT team_id, team_name, parent_id, 1 AS depth FROM teams WHERE parent_id = $1
+            UNION ALL
+            SELECT t.team_id, t.team_name, t.parent_id, tree.depth + 1
+            FROM teams t JOIN tree ON t.parent_id = tree.team_id
+            WHERE tree.depth < $2
+        )
+        SELECT tree.team_id, tree.team_name, tree.parent_id, p.team_name
+        FROM tree
+        JOIN teams p ON p.team_id = tree.parent_id
+        ORDER BY tree.depth, tree.team_name
+    `, teamID, maxTeamDepth)
+	if err != nil {
+		return nil, fmt.Errorf("select subteams: %w", err)
+	}
+
+	teams, err := scanSubteams(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	// участников читаем после закрытия rows, чтобы не держать два соединения
+	for i := range teams {
+		if teams[i].Members, err = r.members(teams[i].ID); err != nil {
+			return nil, err
+		}
+	}
+
+	return teams, nil
+}
+
+func scanSubteams(rows *sql.Rows) ([]domain.Team, error) {
+	defer func() {
+		if cerr := rows.Close(); cerr != nil {
+			log.Println("rows close:", cerr)
+		}
+	}()
+
+	var teams []domain.Team
+	for rows.Next() {
+		var team domain.Team
+		if err := rows.Scan(&team.ID, &team.TeamName, &team.ParentID, &team.ParentTeam); err != nil {
+			return nil, fmt.Errorf("scan row: %w", err)
+		}
+		teams = append(teams, team)
+	}
+
+	return teams, rows.Err()
+}
+
+// SetParent: parentID 0 — команда верхнего уровня; циклы проверяет сервис
+func (r *teamRepository) SetParent(teamID, parentID int64) error {
+	_, err := r.db.Exec(`UPDATE teams SET parent_id = NULLIF($2, 0) WHERE team_id = $1`, teamID, parentID)
+	if err != nil {
+		return fmt.Errorf("update teams: %w", err)
+	}
+	return nil
+}
+
+// команды с тем же родителем; у команды верхнего уровня соседей нет
+func (r *teamRepository) GetSiblingIDs(teamID int64) ([]int64, error) {
+	rows, err := r.db.Query(`
+        SELECT s.team_id
+        FROM teams t
+        JOIN teams s ON s.parent_id = t.parent_id
+        WHERE t.team_id = $1 AND s.team_id != $1
+        ORDER BY s.team_id
+    `, teamID)
+	if err != nil {
+		return nil, fmt.Errorf("select sibling teams: %w", err)
+	}
 
+	return scanTeamIDs(rows)
+}
+
+// родительские команды от ближайшей к корню
+func (r *teamRepository) GetAncestorIDs(teamID int64) ([]int64, error) {
+	rows, err := r.db.Query(`
+        WITH RECURSIVE up AS (
+            SELECT parent_id AS team_id, 1 AS depth FROM teams WHERE team_id = $1 AND parent_id IS NOT NULL
+            UNION ALL
+            SELECT t.parent_id, up.depth + 1
+            FROM teams t JOIN up ON t.team_id = up.team_id
+            WHERE t.parent_id IS NOT NULL AND up.depth < $2
+        )
+        SELECT team_id FROM up ORDER BY depth
+    `, teamID, maxTeamDepth)
+	if err != nil {
+		return nil, fmt.Errorf("select parent teams: %w", err)
+	}
+
+	return scanTeamIDs(rows)
+}
+
+// все лиды команды, включая неактивных и недоступных; отбор кандидатов делает сервис
+func (r *teamRepository) GetLeadIDs(teamID int64) ([]string, error) {
+	rows, err := r.db.Query(`
+        SELECT user_id
+        FROM team_memberships
+        WHERE team_id = $1 AND role = 'lead'
+        ORDER BY user_id
+    `, teamID)
+	if err != nil {
+		return nil, fmt.Errorf("select team leads: %w", err)
+	}
+
+	return scanIDs(rows)
 }
 
 func (r *teamRepository) Exist(team_name string) (bool, error) {
@@ -128,3 +301,230 @@ func (r *teamRepository) Exist(team_name string) (bool, error) {
 	return exist, err
 
 }
+
+// AddMember создаёт пользователя в команде или добавляет существующего ещё в одну команду.
+// Команда становится основной, если primary или у пользователя нет команд;
+// пустая user.Role сохраняет прежнюю роль в команде; исключённый ранее пользователь (team_id NULL) снова становится активным
+func (r *teamRepository) AddMember(teamID int64, user *domain.User, primary bool, events ...domain.Event) error {
+	return r.inTx(func(tx *sql.Tx) error {
+		_, err := tx.Exec(`
+        INSERT INTO users(user_id, username, is_active, team_id, max_open_reviews)
+        VALUES ($1, $2, true, $3, $4)
+        ON CONFLICT (user_id) DO UPDATE
+        SET username = EXCLUDED.username,
+        is_active = CASE WHEN users.team_id IS NULL THEN true ELSE users.is_active END,
+        team_id = CASE WHEN $5 OR users.team_id IS NULL THEN EXCLUDED.team_id ELSE users.team_id END,
+        max_open_reviews = COALESCE(EXCLUDED.max_open_reviews, users.max_open_reviews)`, user.ID, user.UserName, teamID, user.MaxOpenReviews, primary)
+		if err != nil {
+			return fmt.Errorf("upsert users: %w", err)
+		}
+		if err := addMembership(tx, teamID, user.ID, user.Role); err != nil {
+			return err
+		}
+		return insertEvents(tx, events)
+	})
+}
+
+// RemoveMember исключает пользователя из команды. Если это была основная команда,
+// основной становится другая (с меньшим team_id); без команд пользователь деактивируется.
+// PR и ревью не удаляются
+func (r *teamRepository) RemoveMember(teamID int64, userID string, events ...domain.Event) error {
+	return r.inTx(func(tx *sql.Tx) error {
+		_, err := tx.Exec(`DELETE FROM team_memberships WHERE user_id = $1 AND team_id = $2`, userID, teamID)
+		if err != nil {
+			return fmt.Errorf("delete from team_memberships: %w", err)
+		}
+
+		_, err = tx.Exec(`
+        UPDATE users
+        SET team_id = (SELECT MIN(team_id) FROM team_memberships WHERE user_id = $1)
+        WHERE user_id = $1 AND team_id = $2`, userID, teamID)
+		if err == nil {
+			_, err = tx.Exec(`UPDATE users SET is_active = false WHERE user_id = $1 AND team_id IS NULL`, userID)
+		}
+		if err != nil {
+			return fmt.Errorf("update users: %w", err)
+		}
+
+		return insertEvents(tx, events)
+	})
+}
+
+// addMembership: пустая role — member для нового участника, для существующего роль не меняется
+func addMembership(tx *sql.Tx, teamID int64, userID, role string) error {
+	_, err := tx.Exec(`
+        INSERT INTO team_memberships (user_id, team_id, role)
+        VALUES ($1, $2, COALESCE(NULLIF($3, ''), 'member'))
+        ON CONFLICT (user_id, team_id) DO UPDATE
+        SET role = COALESCE(NULLIF($3, ''), team_memberships.role)`, userID, teamID, role)
+	if err != nil {
+		return fmt.Errorf("insert into team_memberships: %w", err)
+	}
+	return nil
+}
+
+func (r *teamRepository) Rename(teamID int64, teamName string) error {
+	_, err := r.db.Exec(`UPDATE teams SET team_name = $2 WHERE team_id = $1`, teamID, teamName)
+	if err != nil {
+		return fmt.Errorf("update teams: %w", err)
+	}
+	return nil
+}
+
+// Delete удаляет пустую команду вместе с настройками, ссылками из fallback_teams и правилами владения
+func (r *teamRepository) Delete(teamID int64) error {
+	_, err := r.db.Exec(`DELETE FROM teams WHERE team_id = $1`, teamID)
+	if err != nil {
+		return fmt.Errorf("delete from teams: %w", err)
+	}
+	return nil
+}
+
+func (r *teamRepository) inTx(fn func(tx *sql.Tx) error) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return errors.New("tx begin: " + err.Error())
+	}
+
+	if err := fn(tx); err != nil {
+		if err := tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+			log.Println("rollback:", err)
+		}
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// настройки команды; если строки нет — значения по умолчанию
+func (r *teamRepository) GetSettings(teamID int64) (*domain.TeamSettings, error) {
+	settings := &domain.TeamSettings{}
+	var strategy, escalation, leadRule, leadPattern sql.NullString
+
+	err := r.db.QueryRow(`
+        SELECT reviewers_count, min_reviewers, required_approvals, strategy, escalation, lead_rule, lead_title_pattern
+        FROM team_settings
+        WHERE team_id = $1
+    `, teamID).Scan(&settings.ReviewersCount, &settings.MinReviewers, &settings.RequiredApprovals, &strategy, &escalation, &leadRule, &leadPattern)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.DefaultTeamSettings(), nil
+		}
+		return nil, fmt.Errorf("select from team_settings: %w", err)
+	}
+
+	settings.Strategy = strategy.String
+	settings.Escalation = escalation.String
+	settings.LeadRule = leadRule.String
+	settings.LeadTitlePattern = leadPattern.String
+
+	return settings, r.loadFallbackTeams(teamID, settings)
+}
+
+func (r *teamRepository) loadFallbackTeams(teamID int64, settings *domain.TeamSettings) error {
+	rows, err := r.db.Query(`
+        SELECT t.team_name
+        FROM team_fallbacks f
+        JOIN teams t ON t.team_id = f.fallback_team_id
+        WHERE f.team_id = $1
+        ORDER BY f.position
+    `, teamID)
+	if err != nil {
+		return fmt.Errorf("select from team_fallbacks: %w", err)
+	}
+
+	names, err := scanIDs(rows)
+	if err != nil {
+		return fmt.Errorf("scan team_fallbacks: %w", err)
+	}
+
+	settings.FallbackTeams = names
+
+	return nil
+}
+
+func (r *teamRepository) UpdateSettings(teamID int64, settings *domain.TeamSettings) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return errors.New("tx begin: " + err.Error())
+	}
+
+	if err := saveSettings(tx, teamID, settings); err != nil {
+		if err := tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+			log.Println("rollback:", err)
+		}
+
+		return fmt.Errorf("save team_settings: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// резервные команды в порядке приоритета
+func (r *teamRepository) GetFallbackTeamIDs(teamID int64) ([]int64, error) {
+	rows, err := r.db.Query(`
+        SELECT fallback_team_id
+        FROM team_fallbacks
+        WHERE team_id = $1
+        ORDER BY position
+    `, teamID)
+	if err != nil {
+		return nil, fmt.Errorf("select from team_fallbacks: %w", err)
+	}
+
+	return scanTeamIDs(rows)
+}
+
+func scanTeamIDs(rows *sql.Rows) ([]int64, error) {
+	defer func() {
+		if cerr := rows.Close(); cerr != nil {
+			log.Println("rows close:", cerr)
+		}
+	}()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+func saveSettings(tx *sql.Tx, teamID int64, settings *domain.TeamSettings) error {
+	_, err := tx.Exec(`
+        INSERT INTO team_settings (team_id, reviewers_count, min_reviewers, required_approvals, strategy, escalation, lead_rule, lead_title_pattern)
+        VALUES ($1, $2, $3, $4, NULLIF($5, ''), NULLIF($6, ''), NULLIF($7, ''), NULLIF($8, ''))
+        ON CONFLICT (team_id) DO UPDATE
+        SET reviewers_count = EXCLUDED.reviewers_count,
+        min_reviewers = EXCLUDED.min_reviewers,
+        required_approvals = EXCLUDED.required_approvals,
+        strategy = EXCLUDED.strategy,
+        escalation = EXCLUDED.escalation,
+        lead_rule = EXCLUDED.lead_rule,
+        lead_title_pattern = EXCLUDED.lead_title_pattern
+    `, teamID, settings.ReviewersCount, settings.MinReviewers, settings.RequiredApprovals, settings.Strategy, settings.Escalation,
+		settings.LeadRule, settings.LeadTitlePattern)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`DELETE FROM team_fallbacks WHERE team_id = $1`, teamID); err != nil {
+		return err
+	}
+
+	for pos, name := range settings.FallbackTeams {
+		_, err := tx.Exec(`
+        INSERT INTO team_fallbacks (team_id, fallback_team_id, position)
+        SELECT $1, team_id, $3 FROM teams WHERE team_name = $2
+        `, teamID, name, pos)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
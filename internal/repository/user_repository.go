@@ -4,12 +4,22 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"log"
 	"pr-reviewer/internal/domain"
+
+	"github.com/lib/pq"
 )
 
 type UserRepository interface {
-	SetIsActive(userId string, value bool) error
+	SetIsActive(userId string, value bool, events ...domain.Event) error
 	GetById(userId string) (*domain.User, string, error)
+	SetMaxOpenReviews(userId string, value *int) error
+	AddUnavailability(period *domain.Unavailability) error
+	ListUnavailability(userId string) ([]domain.Unavailability, error)
+	DeleteUnavailability(id int64) error
+	IsAvailable(userId string) (bool, error)
+	List(filter *domain.UserFilter, afterID string) ([]domain.UserResponse, string, error)
+	SetUsername(userId, username string) error
 }
 
 type userRepository struct {
@@ -20,31 +30,49 @@ func NewUserRepository(db *sql.DB) UserRepository {
 	return &userRepository{db: db}
 }
 
-func (u *userRepository) SetIsActive(userId string, value bool) error {
+// SetIsActive меняет флаг и пишет события в той же транзакции
+func (u *userRepository) SetIsActive(userId string, value bool, events ...domain.Event) error {
 
-	_, err := u.db.Exec(`UPDATE users SET is_active=$1 WHERE user_id=$2`, value, userId)
+	tx, err := u.db.Begin()
+	if err != nil {
+		return errors.New("tx begin: " + err.Error())
+	}
+
+	_, err = tx.Exec(`UPDATE users SET is_active=$1 WHERE user_id=$2`, value, userId)
+	if err == nil {
+		err = insertEvents(tx, events)
+	}
 
 	if err != nil {
+		if err := tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+			log.Println("rollback:", err)
+		}
 		return err
 	}
 
-	return nil
+	return tx.Commit()
 
 }
 
+func (u *userRepository) SetMaxOpenReviews(userId string, value *int) error {
+	_, err := u.db.Exec(`UPDATE users SET max_open_reviews=$1 WHERE user_id=$2`, value, userId)
+	return err
+}
+
 func (u *userRepository) GetById(userId string) (*domain.User, string, error) {
 
 	row := u.db.QueryRow(`
-        SELECT u.user_id, u.username, u.is_active, u.team_id, t.team_name
+        SELECT u.user_id, u.username, u.is_active, COALESCE(u.team_id, 0), u.max_open_reviews, COALESCE(t.team_name, ''),
+            ARRAY(SELECT m.team_id FROM team_memberships m WHERE m.user_id = u.user_id ORDER BY m.team_id)
         FROM users u
-        JOIN teams t ON u.team_id = t.team_id
+        LEFT JOIN teams t ON u.team_id = t.team_id
         WHERE u.user_id = $1
     `, userId)
 
 	user := &domain.User{}
 	var teamName string
 
-	err := row.Scan(&user.ID, &user.UserName, &user.IsActive, &user.TeamID, &teamName)
+	err := row.Scan(&user.ID, &user.UserName, &user.IsActive, &user.TeamID, &user.MaxOpenReviews, &teamName, pq.Array(&user.TeamIDs))
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, "", sql.ErrNoRows
@@ -54,3 +82,124 @@ func (u *userRepository) GetById(userId string) (*domain.User, string, error) {
 
 	return user, teamName, nil
 }
+
+// List — страница пользователей по user_id после afterID; второй результат — user_id
+// последней строки, если есть следующая страница
+func (u *userRepository) List(filter *domain.UserFilter, afterID string) ([]domain.UserResponse, string, error) {
+	rows, err := u.db.Query(`
+        SELECT u.user_id, u.username, u.is_active, u.max_open_reviews, COALESCE(t.team_name, '')
+        FROM users u
+        LEFT JOIN teams t ON u.team_id = t.team_id
+        WHERE ($1 = '' OR EXISTS (
+              SELECT 1 FROM team_memberships m JOIN teams mt ON mt.team_id = m.team_id
+              WHERE m.user_id = u.user_id AND mt.team_name = $1
+          ))
+          AND ($2::boolean IS NULL OR u.is_active = $2)
+          AND ($3 = '' OR u.username ILIKE $3 || '%')
+          AND u.user_id > $4
+        ORDER BY u.user_id
+        LIMIT $5
+    `, filter.TeamName, filter.IsActive, escapeLike(filter.NamePrefix), afterID, filter.Limit+1)
+	if err != nil {
+		return nil, "", fmt.Errorf("select from users: %w", err)
+	}
+
+	defer func() {
+		if cerr := rows.Close(); cerr != nil {
+			log.Println("rows close:", cerr)
+		}
+	}()
+
+	users := []domain.UserResponse{}
+	for rows.Next() {
+		// лишняя строка только сообщает, что есть следующая страница
+		if len(users) == filter.Limit {
+			return users, users[len(users)-1].UserID, rows.Err()
+		}
+
+		var user domain.UserResponse
+		if err := rows.Scan(&user.UserID, &user.UserName, &user.IsActive, &user.MaxOpenReviews, &user.TeamName); err != nil {
+			return nil, "", fmt.Errorf("scan row: %w", err)
+		}
+		users = append(users, user)
+	}
+
+	return users, "", rows.Err()
+}
+
+func (u *userRepository) SetUsername(userId, username string) error {
+	_, err := u.db.Exec(`UPDATE users SET username=$1 WHERE user_id=$2`, username, userId)
+	return err
+}
+
+func (u *userRepository) AddUnavailability(period *domain.Unavailability) error {
+	err := u.db.QueryRow(`
+        INSERT INTO user_unavailability (user_id, starts_at, ends_at, reason)
+        VALUES ($1, $2, $3, $4)
+        RETURNING id
+    `, period.UserID, period.StartsAt, period.EndsAt, period.Reason).Scan(&period.ID)
+	if err != nil {
+		return fmt.Errorf("insert into user_unavailability: %w", err)
+	}
+	return nil
+}
+
+// периоды, которые ещё не закончились
+func (u *userRepository) ListUnavailability(userId string) ([]domain.Unavailability, error) {
+	rows, err := u.db.Query(`
+        SELECT id, user_id, starts_at, ends_at, reason
+        FROM user_unavailability
+        WHERE user_id = $1 AND ends_at > NOW()
+        ORDER BY starts_at
+    `, userId)
+	if err != nil {
+		return nil, fmt.Errorf("select from user_unavailability: %w", err)
+	}
+
+	defer func() {
+		if cerr := rows.Close(); cerr != nil {
+			log.Println("rows close:", cerr)
+		}
+	}()
+
+	periods := []domain.Unavailability{}
+	for rows.Next() {
+		var p domain.Unavailability
+		if err := rows.Scan(&p.ID, &p.UserID, &p.StartsAt, &p.EndsAt, &p.Reason); err != nil {
+			return nil, fmt.Errorf("scan row: %w", err)
+		}
+		periods = append(periods, p)
+	}
+
+	return periods, rows.Err()
+}
+
+// IsAvailable: у пользователя нет активного периода недоступности (то же условие, что при автоподборе)
+func (u *userRepository) IsAvailable(userId string) (bool, error) {
+	var available bool
+	err := u.db.QueryRow(`
+        SELECT `+availableNow+`
+        FROM users WHERE user_id = $1
+    `, userId).Scan(&available)
+	if err != nil {
+		return false, fmt.Errorf("select availability: %w", err)
+	}
+	return available, nil
+}
+
+func (u *userRepository) DeleteUnavailability(id int64) error {
+	res, err := u.db.Exec(`DELETE FROM user_unavailability WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("delete from user_unavailability: %w", err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return domain.ErrNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,184 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"pr-reviewer/internal/domain"
+
+	"github.com/lib/pq"
+)
+
+type WebhookRepository interface {
+	Create(hook *domain.Webhook) error
+	List() ([]domain.Webhook, error)
+	Delete(id int64) error
+	CreateDelivery(delivery *domain.WebhookDelivery) error
+	UpdateDelivery(delivery *domain.WebhookDelivery) error
+	ListDeliveries(webhookID int64, status string) ([]domain.WebhookDelivery, error)
+	PendingDeliveries() ([]domain.PendingDelivery, error)
+}
+
+type webhookRepository struct {
+	db *sql.DB
+}
+
+func NewWebhookRepository(db *sql.DB) WebhookRepository {
+	return &webhookRepository{db: db}
+}
+
+func (r *webhookRepository) Create(hook *domain.Webhook) error {
+	err := r.db.QueryRow(`
+        INSERT INTO webhooks (url, events, secret)
+        VALUES ($1, COALESCE($2, '{}'::TEXT[]), $3)
+        RETURNING webhook_id, created_at
+    `, hook.URL, pq.Array(hook.Events), hook.Secret).Scan(&hook.ID, &hook.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("insert into webhooks: %w", err)
+	}
+
+	return nil
+}
+
+func (r *webhookRepository) List() ([]domain.Webhook, error) {
+	rows, err := r.db.Query(`
+        SELECT webhook_id, url, events, secret, created_at
+        FROM webhooks
+        ORDER BY webhook_id
+    `)
+	if err != nil {
+		return nil, fmt.Errorf("select from webhooks: %w", err)
+	}
+	defer func() {
+		if cerr := rows.Close(); cerr != nil {
+			log.Println("rows close:", cerr)
+		}
+	}()
+
+	hooks := []domain.Webhook{}
+	for rows.Next() {
+		var h domain.Webhook
+		if err := rows.Scan(&h.ID, &h.URL, pq.Array(&h.Events), &h.Secret, &h.CreatedAt); err != nil {
+			return nil, err
+		}
+		hooks = append(hooks, h)
+	}
+
+	return hooks, rows.Err()
+}
+
+func (r *webhookRepository) Delete(id int64) error {
+	res, err := r.db.Exec(`DELETE FROM webhooks WHERE webhook_id=$1`, id)
+	if err != nil {
+		return fmt.Errorf("delete from webhooks: %w", err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return domain.ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *webhookRepository) CreateDelivery(d *domain.WebhookDelivery) error {
+	err := r.db.QueryRow(`
+        INSERT INTO webhook_deliveries (webhook_id, event_id, event_type, status)
+        VALUES ($1, $2, $3, $4)
+        RETURNING delivery_id, created_at, updated_at
+    `, d.WebhookID, d.EventID, d.EventType, d.Status).Scan(&d.ID, &d.CreatedAt, &d.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("insert into webhook_deliveries: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateDelivery сохраняет результат очередной попытки
+func (r *webhookRepository) UpdateDelivery(d *domain.WebhookDelivery) error {
+	err := r.db.QueryRow(`
+        UPDATE webhook_deliveries
+        SET status=$2, attempts=$3, response_code=$4, last_error=$5, updated_at=NOW()
+        WHERE delivery_id=$1
+        RETURNING updated_at
+    `, d.ID, d.Status, d.Attempts, d.ResponseCode, d.LastError).Scan(&d.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("update webhook_deliveries: %w", err)
+	}
+
+	return nil
+}
+
+// PendingDeliveries — доставки в статусе PENDING вместе с подпиской и событием, в порядке создания
+func (r *webhookRepository) PendingDeliveries() ([]domain.PendingDelivery, error) {
+	rows, err := r.db.Query(`
+        SELECT d.delivery_id, d.webhook_id, d.event_id, d.event_type, d.status, d.attempts, d.response_code, d.last_error, d.created_at, d.updated_at,
+               w.url, w.events, w.secret,
+               COALESCE(e.pull_request_id, ''), COALESCE(e.user_id, ''), COALESCE(e.actor, ''), e.payload, e.created_at
+        FROM webhook_deliveries d
+        JOIN webhooks w ON w.webhook_id = d.webhook_id
+        JOIN events e ON e.event_id = d.event_id
+        WHERE d.status = 'PENDING'
+        ORDER BY d.delivery_id
+    `)
+	if err != nil {
+		return nil, fmt.Errorf("select from webhook_deliveries: %w", err)
+	}
+	defer func() {
+		if cerr := rows.Close(); cerr != nil {
+			log.Println("rows close:", cerr)
+		}
+	}()
+
+	pending := []domain.PendingDelivery{}
+	for rows.Next() {
+		var p domain.PendingDelivery
+		var payload []byte
+		d := &p.Delivery
+		err := rows.Scan(&d.ID, &d.WebhookID, &d.EventID, &d.EventType, &d.Status, &d.Attempts, &d.ResponseCode, &d.LastError, &d.CreatedAt, &d.UpdatedAt,
+			&p.Webhook.URL, pq.Array(&p.Webhook.Events), &p.Webhook.Secret,
+			&p.Event.PullRequestID, &p.Event.UserID, &p.Event.Actor, &payload, &p.Event.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		p.Webhook.ID = d.WebhookID
+		p.Event.ID, p.Event.Type, p.Event.Payload = d.EventID, d.EventType, payload
+		pending = append(pending, p)
+	}
+
+	return pending, rows.Err()
+}
+
+// ListDeliveries — журнал доставок подписки, новые первыми; пустой status — все
+func (r *webhookRepository) ListDeliveries(webhookID int64, status string) ([]domain.WebhookDelivery, error) {
+	rows, err := r.db.Query(`
+        SELECT delivery_id, webhook_id, event_id, event_type, status, attempts, response_code, last_error, created_at, updated_at
+        FROM webhook_deliveries
+        WHERE webhook_id=$1 AND ($2 = '' OR status = $2)
+        ORDER BY delivery_id DESC
+        LIMIT 100
+    `, webhookID, status)
+	if err != nil {
+		return nil, fmt.Errorf("select from webhook_deliveries: %w", err)
+	}
+	defer func() {
+		if cerr := rows.Close(); cerr != nil {
+			log.Println("rows close:", cerr)
+		}
+	}()
+
+	deliveries := []domain.WebhookDelivery{}
+	for rows.Next() {
+		var d domain.WebhookDelivery
+		err := rows.Scan(&d.ID, &d.WebhookID, &d.EventID, &d.EventType, &d.Status, &d.Attempts, &d.ResponseCode, &d.LastError, &d.CreatedAt, &d.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, d)
+	}
+
+	return deliveries, rows.Err()
+}
@@ -0,0 +1,392 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"pr-reviewer/internal/domain"
+	"regexp"
+	"slices"
+)
+
+// эскалация: где искать замену ревьювера, если в целевой и резервных командах никого нет
+const (
+	EscalationNone           = "none"
+	EscalationSiblings       = "siblings"        // команды с тем же родителем
+	EscalationParent         = "parent"          // родительские команды вверх до корня
+	EscalationSiblingsParent = "siblings_parent" // сначала соседние, затем родительские
+)
+
+func IsKnownEscalation(name string) bool {
+	switch name {
+	case EscalationNone, EscalationSiblings, EscalationParent, EscalationSiblingsParent:
+		return true
+	}
+	return false
+}
+
+// lead_rule: когда среди ревьюверов обязателен лид целевой команды
+const (
+	LeadRuleNone   = "none"
+	LeadRuleAlways = "always"
+	LeadRuleTitle  = "title" // название PR совпадает с lead_title_pattern
+)
+
+func IsKnownLeadRule(name string) bool {
+	switch name {
+	case LeadRuleNone, LeadRuleAlways, LeadRuleTitle:
+		return true
+	}
+	return false
+}
+
+// leadRequired — нужен ли лид для PR с таким названием; шаблон проверен при сохранении настроек
+func leadRequired(settings *domain.TeamSettings, title string) bool {
+	switch settings.LeadRule {
+	case LeadRuleAlways:
+		return true
+	case LeadRuleTitle:
+		re, err := regexp.Compile(settings.LeadTitlePattern)
+		return err == nil && re.MatchString(title)
+	}
+	return false
+}
+
+// selectReviewers подбирает ревьюверов по настройкам целевой команды PR: сначала владельцы
+// изменённых файлов, затем целевая команда и её резервные команды; по lead_rule среди них обязателен лид.
+// Если ревьюверов меньше нужного из-за лимитов max_open_reviews, возвращает предупреждение NO_CAPACITY
+// (или ошибку ErrNoCapacity, если не набрался min_reviewers)
+func (s *pullRequestService) selectReviewers(pr *domain.PullRequest) ([]domain.Reviewer, []domain.Warning, error) {
+	settings, err := s.teams.GetSettings(pr.TeamID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	selector := s.selectors.ForTeam(pr.TeamName, settings)
+
+	reviewers, err := s.pickOwners(pr, selector, settings.ReviewersCount)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	reviewers, err = s.pickReviewers(pr, selector, reviewers, settings.ReviewersCount)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	reviewers, err = s.ensureLead(pr, settings, selector, reviewers)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(reviewers) >= settings.ReviewersCount {
+		return reviewers, nil, nil
+	}
+
+	atCapacity, err := s.countAtCapacity(pr, reviewerIDs(reviewers))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(reviewers) < settings.MinReviewers {
+		if atCapacity > 0 {
+			return nil, nil, domain.ErrNoCapacity
+		}
+		return nil, nil, domain.ErrNotEnoughReviewers
+	}
+
+	var warnings []domain.Warning
+	if atCapacity > 0 {
+		warnings = append(warnings, domain.Warning{
+			Code:    domain.ErrNoCapacity.Error(),
+			Message: fmt.Sprintf("assigned %d of %d reviewers: %d candidates reached max_open_reviews", len(reviewers), settings.ReviewersCount, atCapacity),
+		})
+	}
+
+	return reviewers, warnings, nil
+}
+
+// сколько кандидатов целевой и резервных команд пропущено из-за лимита ревью
+func (s *pullRequestService) countAtCapacity(pr *domain.PullRequest, exclude []string) (int, error) {
+	fallbackTeams, err := s.teams.GetFallbackTeamIDs(pr.TeamID)
+	if err != nil {
+		return 0, err
+	}
+
+	teams := append([]int64{pr.TeamID}, fallbackTeams...)
+	return s.repo.CountAtCapacity(teams, append(exclude, pr.AuthorID))
+}
+
+// pickOwners назначает по одному владельцу на каждое правило, совпавшее с изменёнными файлами
+func (s *pullRequestService) pickOwners(pr *domain.PullRequest, selector ReviewerSelector, count int) ([]domain.Reviewer, error) {
+	if len(pr.ChangedFiles) == 0 || count <= 0 {
+		return nil, nil
+	}
+
+	rules, err := s.owners.List()
+	if err != nil {
+		return nil, err
+	}
+
+	var picked []domain.Reviewer
+	for _, rule := range MatchOwnershipRules(rules, pr.ChangedFiles) {
+		if len(picked) >= count {
+			break
+		}
+
+		var candidates []string
+		teamID := rule.TeamID
+		if rule.UserID != "" {
+			candidates, err = s.repo.FilterEligible([]string{rule.UserID}, pr.AuthorID)
+			teamID = pr.TeamID
+		} else {
+			candidates, err = s.repo.GetTeamMembers(rule.TeamID, pr.AuthorID)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		candidates = slices.DeleteFunc(candidates, func(id string) bool {
+			return slices.Contains(reviewerIDs(picked), id)
+		})
+
+		// единственного кандидата берём напрямую: Select у round_robin сдвинул бы очередь команды
+		selected := candidates
+		if len(candidates) > 1 {
+			selected, err = selector.Select(teamID, candidates, 1)
+			if err != nil {
+				return nil, err
+			}
+		}
+		for _, id := range selected {
+			picked = append(picked, domain.Reviewer{UserID: id, OwnerRule: rule.Pattern})
+		}
+	}
+
+	return picked, nil
+}
+
+// pickReviewers добирает до count ревьюверов из целевой команды PR,
+// затем из её резервных команд в порядке приоритета
+func (s *pullRequestService) pickReviewers(pr *domain.PullRequest, selector ReviewerSelector, picked []domain.Reviewer, count int) ([]domain.Reviewer, error) {
+	teams := []int64{pr.TeamID}
+
+	for i := 0; i < len(teams); i++ {
+		need := count - len(picked)
+		if need <= 0 {
+			break
+		}
+
+		teamID := teams[i]
+
+		candidates, err := s.repo.GetTeamMembers(teamID, pr.AuthorID)
+		if err != nil {
+			return nil, err
+		}
+		candidates = slices.DeleteFunc(candidates, func(id string) bool {
+			return slices.Contains(reviewerIDs(picked), id)
+		})
+
+		selected, err := selector.Select(teamID, candidates, need)
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range selected {
+			picked = append(picked, domain.Reviewer{UserID: id, Fallback: teamID != pr.TeamID})
+		}
+
+		// резервные команды нужны, только если своей не хватило
+		if i == 0 && len(picked) < count {
+			fallbackTeams, err := s.teams.GetFallbackTeamIDs(pr.TeamID)
+			if err != nil {
+				return nil, err
+			}
+			teams = append(teams, fallbackTeams...)
+		}
+	}
+
+	return picked, nil
+}
+
+// ensureLead добавляет лида целевой команды, если его нет среди picked, а lead_rule его требует.
+// Если мест не осталось, лид заменяет последнего ревьювера не по правилу владения.
+// Автор-лид считается увиденным PR; без доступного лида — ErrLeadRequired
+func (s *pullRequestService) ensureLead(pr *domain.PullRequest, settings *domain.TeamSettings, selector ReviewerSelector, picked []domain.Reviewer) ([]domain.Reviewer, error) {
+	if settings.ReviewersCount <= 0 || !leadRequired(settings, pr.Name) {
+		return picked, nil
+	}
+
+	leads, err := s.teams.GetLeadIDs(pr.TeamID)
+	if err != nil {
+		return nil, err
+	}
+
+	if slices.Contains(leads, pr.AuthorID) || slices.ContainsFunc(reviewerIDs(picked), func(id string) bool {
+		return slices.Contains(leads, id)
+	}) {
+		return picked, nil
+	}
+
+	candidates, err := s.repo.GetTeamMembers(pr.TeamID, pr.AuthorID)
+	if err != nil {
+		return nil, err
+	}
+	candidates = slices.DeleteFunc(candidates, func(id string) bool {
+		return !slices.Contains(leads, id)
+	})
+
+	selected, err := selector.Select(pr.TeamID, candidates, 1)
+	if err != nil {
+		return nil, err
+	}
+	if len(selected) == 0 {
+		return nil, domain.ErrLeadRequired
+	}
+
+	lead := domain.Reviewer{UserID: selected[0]}
+	if len(picked) < settings.ReviewersCount {
+		return append(picked, lead), nil
+	}
+
+	idx := len(picked) - 1
+	for i := len(picked) - 1; i >= 0; i-- {
+		if picked[i].OwnerRule == "" {
+			idx = i
+			break
+		}
+	}
+	picked[idx] = lead
+
+	return picked, nil
+}
+
+// replacingLead — true, если oldReviewerID единственный назначенный лид, а lead_rule требует лида:
+// тогда замена тоже должна быть лидом. Второй результат — все лиды целевой команды
+func (s *pullRequestService) replacingLead(pr *domain.PullRequest, oldReviewerID string, settings *domain.TeamSettings) (bool, []string, error) {
+	if !leadRequired(settings, pr.Name) {
+		return false, nil, nil
+	}
+
+	leads, err := s.teams.GetLeadIDs(pr.TeamID)
+	if err != nil {
+		return false, nil, err
+	}
+
+	if !slices.Contains(leads, oldReviewerID) || slices.Contains(leads, pr.AuthorID) {
+		return false, leads, nil
+	}
+
+	for _, id := range pr.ReviewerIDs() {
+		if id != oldReviewerID && slices.Contains(leads, id) {
+			return false, leads, nil
+		}
+	}
+
+	return true, leads, nil
+}
+
+// pickLeadReplacement ищет замену лиду только среди лидов целевой команды
+func (s *pullRequestService) pickLeadReplacement(pr *domain.PullRequest, oldReviewerID string, leads []string, selector ReviewerSelector) (string, error) {
+	candidates, err := s.repo.FindReplacement(pr.TeamID, pr.AuthorID, oldReviewerID, pr.ReviewerIDs())
+	if err != nil {
+		if errors.Is(err, domain.ErrNoCandidate) {
+			return "", domain.ErrLeadRequired
+		}
+		return "", err
+	}
+	candidates = slices.DeleteFunc(candidates, func(id string) bool {
+		return !slices.Contains(leads, id)
+	})
+
+	selected, err := selector.Select(pr.TeamID, candidates, 1)
+	if err != nil {
+		return "", err
+	}
+	if len(selected) == 0 {
+		return "", domain.ErrLeadRequired
+	}
+
+	return selected[0], nil
+}
+
+func reviewerIDs(reviewers []domain.Reviewer) []string {
+	ids := make([]string, 0, len(reviewers))
+	for _, r := range reviewers {
+		ids = append(ids, r.UserID)
+	}
+	return ids
+}
+
+// pickReplacement ищет замену сначала в целевой команде PR, затем в её резервных командах
+// и, по политике escalation, в соседних и родительских командах.
+// Второй результат — true, если замена не из целевой команды
+func (s *pullRequestService) pickReplacement(pr *domain.PullRequest, oldReviewerID string, settings *domain.TeamSettings, selector ReviewerSelector) (string, bool, error) {
+	fallbackTeams, err := s.teams.GetFallbackTeamIDs(pr.TeamID)
+	if err != nil {
+		return "", false, err
+	}
+
+	escalation, err := s.escalationTeams(pr.TeamID, settings.Escalation)
+	if err != nil {
+		return "", false, err
+	}
+
+	teams := []int64{pr.TeamID}
+	for _, teamID := range slices.Concat(fallbackTeams, escalation) {
+		if !slices.Contains(teams, teamID) {
+			teams = append(teams, teamID)
+		}
+	}
+
+	for _, teamID := range teams {
+		candidates, err := s.repo.FindReplacement(teamID, pr.AuthorID, oldReviewerID, pr.ReviewerIDs())
+		if err != nil {
+			if errors.Is(err, domain.ErrNoCandidate) {
+				continue
+			}
+			return "", false, err
+		}
+
+		selected, err := selector.Select(teamID, candidates, 1)
+		if err != nil {
+			return "", false, err
+		}
+		if len(selected) > 0 {
+			return selected[0], teamID != pr.TeamID, nil
+		}
+	}
+
+	// никого нет: отличаем «все заняты» от «некого назначить»
+	exclude := append(pr.ReviewerIDs(), pr.AuthorID)
+	atCapacity, err := s.repo.CountAtCapacity(teams, exclude)
+	if err != nil {
+		return "", false, err
+	}
+	if atCapacity > 0 {
+		return "", false, domain.ErrNoCapacity
+	}
+
+	return "", false, domain.ErrNoCandidate
+}
+
+// escalationTeams — соседние и/или родительские команды в порядке обхода
+func (s *pullRequestService) escalationTeams(teamID int64, policy string) ([]int64, error) {
+	var teams []int64
+
+	if policy == EscalationSiblings || policy == EscalationSiblingsParent {
+		siblings, err := s.teams.GetSiblingIDs(teamID)
+		if err != nil {
+			return nil, err
+		}
+		teams = append(teams, siblings...)
+	}
+
+	if policy == EscalationParent || policy == EscalationSiblingsParent {
+		ancestors, err := s.teams.GetAncestorIDs(teamID)
+		if err != nil {
+			return nil, err
+		}
+		teams = append(teams, ancestors...)
+	}
+
+	return teams, nil
+}
@@ -0,0 +1,240 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"pr-reviewer/internal/domain"
+	"pr-reviewer/internal/repository"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ReviewRequester сообщает code host о назначенных ревьюверах. Вызов не блокирует
+type ReviewRequester interface {
+	RequestReviewers(prID string, userIDs []string)
+}
+
+// CodeHostClient — API code host для запроса ревью
+type CodeHostClient interface {
+	RequestReviewers(ctx context.Context, pr *domain.CodeHostPullRequest, logins []string) error
+}
+
+// GitHubClient — POST /repos/{owner}/{repo}/pulls/{number}/requested_reviewers
+type GitHubClient struct {
+	baseURL string
+	token   string
+	client  *http.Client
+}
+
+func NewGitHubClient(baseURL, token string, client *http.Client) *GitHubClient {
+	return &GitHubClient{baseURL: strings.TrimRight(baseURL, "/"), token: token, client: client}
+}
+
+func (c *GitHubClient) RequestReviewers(ctx context.Context, pr *domain.CodeHostPullRequest, logins []string) error {
+	body, err := json.Marshal(map[string]any{"reviewers": logins})
+	if err != nil {
+		return err
+	}
+
+	url := fmt.Sprintf("%s/repos/%s/pulls/%d/requested_reviewers", c.baseURL, pr.Repository, pr.Number)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := resp.Body.Close(); cerr != nil {
+			log.Println("github response close:", cerr)
+		}
+	}()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return &CodeHostStatusError{Op: "github requested_reviewers", StatusCode: resp.StatusCode, Body: string(bytes.TrimSpace(msg))}
+	}
+	return nil
+}
+
+// CodeHostStatusError — code host ответил не-2xx
+type CodeHostStatusError struct {
+	Op         string
+	StatusCode int
+	Body       string
+}
+
+func (e *CodeHostStatusError) Error() string {
+	return fmt.Sprintf("%s: status %d: %s", e.Op, e.StatusCode, e.Body)
+}
+
+// Temporary: 5xx и 429 могут пройти при повторе, остальные 4xx (422 — не коллаборатор и т.п.) — нет
+func (e *CodeHostStatusError) Temporary() bool {
+	return e.StatusCode >= 500 || e.StatusCode == http.StatusTooManyRequests
+}
+
+// retryable: повторяем сетевые ошибки и ошибки БД, а ответы code host — только временные
+func retryable(err error) bool {
+	var statusErr *CodeHostStatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.Temporary()
+	}
+	return true
+}
+
+// FakeCodeHostClient запоминает запросы вместо обращения к code host (локальный запуск, тесты).
+// Первые FailTimes вызовов возвращают ошибку
+type FakeCodeHostClient struct {
+	FailTimes int
+
+	mu       sync.Mutex
+	calls    int
+	requests []FakeReviewRequest
+}
+
+type FakeReviewRequest struct {
+	PullRequest domain.CodeHostPullRequest
+	Logins      []string
+}
+
+func (c *FakeCodeHostClient) RequestReviewers(_ context.Context, pr *domain.CodeHostPullRequest, logins []string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.calls++
+	if c.calls <= c.FailTimes {
+		return errors.New("fake code host: unavailable")
+	}
+
+	log.Printf("fake code host: %s %s#%d requested reviewers %v", pr.Provider, pr.Repository, pr.Number, logins)
+	c.requests = append(c.requests, FakeReviewRequest{PullRequest: *pr, Logins: logins})
+	return nil
+}
+
+// Requests — успешные запросы
+func (c *FakeCodeHostClient) Requests() []FakeReviewRequest {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return slices.Clone(c.requests)
+}
+
+// CodeHostReviewRequester отправляет запрос ревью в code host, из которого пришёл PR.
+// PR без связи с code host и ревьюверы без связанного логина пропускаются;
+// сетевые ошибки, 5xx и 429 повторяются в фоне с экспоненциальной задержкой.
+// Повторы живут только в памяти: при остановке процесса недоотправленные запросы
+// пишутся в лог и теряются (в отличие от вебхуков, которые хранятся в БД)
+type CodeHostReviewRequester struct {
+	links       repository.CodeHostRepository
+	clients     map[string]CodeHostClient // provider -> клиент
+	maxAttempts int
+	backoff     time.Duration // задержка перед второй попыткой, дальше удваивается
+	timeout     time.Duration
+
+	wg       sync.WaitGroup
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+func NewCodeHostReviewRequester(links repository.CodeHostRepository, clients map[string]CodeHostClient, maxAttempts int, backoff, timeout time.Duration) *CodeHostReviewRequester {
+	return &CodeHostReviewRequester{links: links, clients: clients, maxAttempts: max(maxAttempts, 1), backoff: backoff, timeout: timeout, stop: make(chan struct{})}
+}
+
+func (r *CodeHostReviewRequester) RequestReviewers(prID string, userIDs []string) {
+	if len(userIDs) == 0 {
+		return
+	}
+
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		r.request(prID, userIDs)
+	}()
+}
+
+// Wait ждёт завершения начатых запросов
+func (r *CodeHostReviewRequester) Wait() {
+	r.wg.Wait()
+}
+
+// Shutdown прерывает ожидание повторов и ждёт текущие попытки
+func (r *CodeHostReviewRequester) Shutdown() {
+	r.stopOnce.Do(func() { close(r.stop) })
+	r.wg.Wait()
+}
+
+func (r *CodeHostReviewRequester) request(prID string, userIDs []string) {
+	delay := r.backoff
+
+	for attempt := 1; ; attempt++ {
+		done, err := r.try(prID, userIDs)
+		if done {
+			return
+		}
+
+		if !retryable(err) {
+			log.Printf("code host review request for %s failed: %v", prID, err)
+			return
+		}
+
+		if attempt >= r.maxAttempts {
+			log.Printf("code host review request for %s failed after %d attempts: %v", prID, attempt, err)
+			return
+		}
+
+		select {
+		case <-r.stop:
+			log.Printf("code host review request for %s %v dropped on shutdown after %d attempts: %v", prID, userIDs, attempt, err)
+			return
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
+
+// try возвращает true, если повтор не нужен
+func (r *CodeHostReviewRequester) try(prID string, userIDs []string) (bool, error) {
+	link, err := r.links.GetLink(prID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return true, nil
+		}
+		return false, err
+	}
+
+	client, ok := r.clients[link.Provider]
+	if !ok {
+		return true, nil
+	}
+
+	logins, err := r.links.Logins(link.Provider, userIDs)
+	if err != nil {
+		return false, err
+	}
+	if len(logins) == 0 {
+		log.Printf("code host review request for %s skipped: no %s logins for %v", prID, link.Provider, userIDs)
+		return true, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), r.timeout)
+	defer cancel()
+
+	if err := client.RequestReviewers(ctx, link, logins); err != nil {
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,56 @@
+package services
+
+import (
+	"encoding/json"
+	"log"
+	"pr-reviewer/internal/domain"
+)
+
+// EventPublisher получает события из outbox (например, для вебхуков).
+// Ошибка — событие будет передано повторно
+type EventPublisher interface {
+	Publish(event domain.Event) error
+}
+
+// newEvent собирает событие журнала; ошибка сериализации payload только логируется
+func newEvent(event domain.Event, payload any) domain.Event {
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			log.Println("marshal event payload:", err)
+			return event
+		}
+		event.Payload = data
+	}
+	return event
+}
+
+func prEvent(prID, actor, eventType string, payload any) domain.Event {
+	return newEvent(domain.Event{PullRequestID: prID, Actor: actor, Type: eventType}, payload)
+}
+
+func assignedEvent(pr *domain.PullRequest, actor string) domain.Event {
+	return prEvent(pr.ID, actor, domain.EventReviewersAssigned, map[string]any{"reviewers": pr.AssignedReviewers, "warnings": pr.Warnings})
+}
+
+// History возвращает журнал событий PR в хронологическом порядке.
+// История остаётся доступной и после удаления самого PR
+func (s *pullRequestService) History(prID string) ([]domain.Event, error) {
+	events, err := s.events.ListByPullRequest(prID)
+	if err != nil {
+		return nil, err
+	}
+	if len(events) > 0 {
+		return events, nil
+	}
+
+	exists, err := s.repo.Exists(prID)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, domain.ErrNotFound
+	}
+
+	return events, nil
+}
@@ -0,0 +1,275 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/subtle"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"pr-reviewer/internal/domain"
+	"pr-reviewer/internal/repository"
+	"strconv"
+)
+
+// длина pull_requests.title
+const maxTitleLength = 100
+
+type IntegrationService interface {
+	Apply(event *domain.CodeHostEvent) (*domain.PullRequest, error)
+	AddIdentity(identity *domain.Identity) error
+	ListIdentities(userID string) ([]domain.Identity, error)
+	DeleteIdentity(provider, login string) error
+}
+
+type integrationService struct {
+	prs        PullRequestService
+	identities repository.IdentityRepository
+	users      repository.UserRepository
+	links      repository.CodeHostRepository
+}
+
+func NewIntegrationService(prs PullRequestService, ir repository.IdentityRepository, ur repository.UserRepository, lr repository.CodeHostRepository) IntegrationService {
+	return &integrationService{prs: prs, identities: ir, users: ur, links: lr}
+}
+
+// Apply переводит событие code host в вызов PullRequestService
+func (s *integrationService) Apply(event *domain.CodeHostEvent) (*domain.PullRequest, error) {
+	actor := s.actor(event.Provider, event.SenderLogin)
+
+	switch event.Action {
+	case domain.CodeHostOpened:
+		authorID, err := s.identities.Resolve(event.Provider, event.AuthorLogin)
+		if err != nil {
+			if errors.Is(err, domain.ErrNotFound) {
+				return nil, domain.ErrUnknownIdentity
+			}
+			return nil, err
+		}
+
+		pr := &domain.PullRequest{
+			ID:       event.PullRequestID,
+			Name:     truncate(event.Title, maxTitleLength),
+			AuthorID: authorID,
+		}
+		if event.Draft {
+			pr.Status = domain.StatusDraft
+		}
+
+		// связь нужна до Create: запрос ревью в code host отправляется сразу после назначения
+		if event.Repository != "" && event.Number != 0 {
+			err := s.links.SaveLink(&domain.CodeHostPullRequest{
+				PullRequestID: event.PullRequestID,
+				Provider:      event.Provider,
+				Repository:    event.Repository,
+				Number:        event.Number,
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		return s.prs.Create(pr, actor)
+
+	// merge уже произошёл на code host, проверку approve не применяем
+	case domain.CodeHostMerged:
+		return s.prs.Merge(event.PullRequestID, true, actor)
+	case domain.CodeHostClosed:
+		return s.prs.Close(event.PullRequestID, actor)
+	case domain.CodeHostReopened:
+		return s.prs.Reopen(event.PullRequestID, actor)
+	case domain.CodeHostReady:
+		return s.prs.Ready(event.PullRequestID, actor)
+	}
+
+	return nil, domain.ErrInvalidPayload
+}
+
+// actor — user_id отправителя, если логин связан, иначе "provider:login"
+func (s *integrationService) actor(provider, login string) string {
+	if login == "" {
+		return provider
+	}
+	if userID, err := s.identities.Resolve(provider, login); err == nil {
+		return userID
+	}
+	return truncate(provider+":"+login, 50)
+}
+
+func (s *integrationService) AddIdentity(identity *domain.Identity) error {
+	if _, _, err := s.users.GetById(identity.UserID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.ErrNotFound
+		}
+		return err
+	}
+
+	return s.identities.Upsert(identity)
+}
+
+func (s *integrationService) ListIdentities(userID string) ([]domain.Identity, error) {
+	return s.identities.List(userID)
+}
+
+func (s *integrationService) DeleteIdentity(provider, login string) error {
+	return s.identities.Delete(provider, login)
+}
+
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n])
+}
+
+// VerifyGitHubSignature проверяет заголовок X-Hub-Signature-256
+func VerifyGitHubSignature(secret string, body []byte, signature string) bool {
+	return secret != "" && hmac.Equal([]byte(SignPayload(secret, body)), []byte(signature))
+}
+
+// VerifyGitLabToken проверяет заголовок X-Gitlab-Token
+func VerifyGitLabToken(secret, token string) bool {
+	return secret != "" && subtle.ConstantTimeCompare([]byte(secret), []byte(token)) == 1
+}
+
+type githubPullRequestEvent struct {
+	Action      string `json:"action"`
+	PullRequest *struct {
+		ID     int64  `json:"id"`
+		Number int64  `json:"number"`
+		Title  string `json:"title"`
+		Draft  bool   `json:"draft"`
+		Merged bool   `json:"merged"`
+		User   struct {
+			Login string `json:"login"`
+		} `json:"user"`
+	} `json:"pull_request"`
+	Sender struct {
+		Login string `json:"login"`
+	} `json:"sender"`
+	Repository struct {
+		FullName string `json:"full_name"`
+	} `json:"repository"`
+}
+
+// ParseGitHubEvent разбирает событие pull_request (заголовок X-GitHub-Event).
+// nil без ошибки — событие не влияет на сервис
+func ParseGitHubEvent(eventType string, body []byte) (*domain.CodeHostEvent, error) {
+	if eventType != "pull_request" {
+		return nil, nil
+	}
+
+	var payload githubPullRequestEvent
+	if err := json.Unmarshal(body, &payload); err != nil || payload.PullRequest == nil || payload.PullRequest.ID == 0 {
+		return nil, domain.ErrInvalidPayload
+	}
+
+	event := &domain.CodeHostEvent{
+		Provider:      domain.ProviderGitHub,
+		PullRequestID: "github-" + strconv.FormatInt(payload.PullRequest.ID, 10),
+		Title:         payload.PullRequest.Title,
+		AuthorLogin:   payload.PullRequest.User.Login,
+		SenderLogin:   payload.Sender.Login,
+		Draft:         payload.PullRequest.Draft,
+		Repository:    payload.Repository.FullName,
+		Number:        payload.PullRequest.Number,
+	}
+
+	switch payload.Action {
+	case "opened":
+		event.Action = domain.CodeHostOpened
+	case "closed":
+		event.Action = domain.CodeHostClosed
+		if payload.PullRequest.Merged {
+			event.Action = domain.CodeHostMerged
+		}
+	case "reopened":
+		event.Action = domain.CodeHostReopened
+	case "ready_for_review":
+		event.Action = domain.CodeHostReady
+	default:
+		return nil, nil
+	}
+
+	return event, nil
+}
+
+type gitlabMergeRequestEvent struct {
+	ObjectKind string `json:"object_kind"`
+	User       struct {
+		Username string `json:"username"`
+	} `json:"user"`
+	Project struct {
+		PathWithNamespace string `json:"path_with_namespace"`
+	} `json:"project"`
+	ObjectAttributes *struct {
+		ID             int64  `json:"id"`
+		IID            int64  `json:"iid"`
+		Title          string `json:"title"`
+		Action         string `json:"action"`
+		Draft          bool   `json:"draft"`
+		WorkInProgress bool   `json:"work_in_progress"`
+	} `json:"object_attributes"`
+	Changes struct {
+		Draft          *gitlabBoolChange `json:"draft"`
+		WorkInProgress *gitlabBoolChange `json:"work_in_progress"`
+	} `json:"changes"`
+}
+
+type gitlabBoolChange struct {
+	Previous bool `json:"previous"`
+	Current  bool `json:"current"`
+}
+
+// undrafted — изменение снимает черновик
+func (c *gitlabBoolChange) undrafted() bool {
+	return c != nil && c.Previous && !c.Current
+}
+
+// ParseGitLabEvent разбирает Merge Request Hook (заголовок X-Gitlab-Event).
+// В payload GitLab у автора MR только числовой id, поэтому при open автором
+// считается пользователь, открывший MR (поле user)
+func ParseGitLabEvent(eventType string, body []byte) (*domain.CodeHostEvent, error) {
+	if eventType != "Merge Request Hook" {
+		return nil, nil
+	}
+
+	var payload gitlabMergeRequestEvent
+	if err := json.Unmarshal(body, &payload); err != nil || payload.ObjectKind != "merge_request" ||
+		payload.ObjectAttributes == nil || payload.ObjectAttributes.ID == 0 {
+		return nil, domain.ErrInvalidPayload
+	}
+
+	attrs := payload.ObjectAttributes
+	event := &domain.CodeHostEvent{
+		Provider:      domain.ProviderGitLab,
+		PullRequestID: "gitlab-" + strconv.FormatInt(attrs.ID, 10),
+		Title:         attrs.Title,
+		AuthorLogin:   payload.User.Username,
+		SenderLogin:   payload.User.Username,
+		Draft:         attrs.Draft || attrs.WorkInProgress,
+		Repository:    payload.Project.PathWithNamespace,
+		Number:        attrs.IID,
+	}
+
+	switch attrs.Action {
+	case "open":
+		event.Action = domain.CodeHostOpened
+	case "merge":
+		event.Action = domain.CodeHostMerged
+	case "close":
+		event.Action = domain.CodeHostClosed
+	case "reopen":
+		event.Action = domain.CodeHostReopened
+	case "update":
+		// из update интересен только выход из черновика, остальные правки игнорируем
+		if !payload.Changes.Draft.undrafted() && !payload.Changes.WorkInProgress.undrafted() {
+			return nil, nil
+		}
+		event.Action = domain.CodeHostReady
+	default:
+		return nil, nil
+	}
+
+	return event, nil
+}
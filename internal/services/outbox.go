@@ -0,0 +1,85 @@
+package services
+
+import (
+	"context"
+	"log"
+	"pr-reviewer/internal/repository"
+	"time"
+)
+
+// максимальная задержка между повторами одного сообщения
+const maxOutboxRetryDelay = 10 * time.Minute
+
+// OutboxDispatcher разбирает outbox и передаёт события publisher'у.
+// Сообщение отмечается обработанным только после успешной передачи, поэтому
+// после падения процесса событие будет отправлено повторно (at-least-once)
+type OutboxDispatcher struct {
+	repo      repository.OutboxRepository
+	publisher EventPublisher
+	interval  time.Duration
+	batchSize int
+	backoff   time.Duration // задержка перед первым повтором, дальше удваивается
+}
+
+func NewOutboxDispatcher(r repository.OutboxRepository, p EventPublisher, interval time.Duration, batchSize int, backoff time.Duration) *OutboxDispatcher {
+	return &OutboxDispatcher{repo: r, publisher: p, interval: interval, batchSize: max(batchSize, 1), backoff: backoff}
+}
+
+// Run опрашивает outbox каждые interval до отмены ctx
+func (d *OutboxDispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		// разбираем пачками, пока очередь не опустеет
+		for {
+			n, err := d.Drain()
+			if err != nil {
+				log.Println("outbox:", err)
+				break
+			}
+			if n < d.batchSize {
+				break
+			}
+		}
+	}
+}
+
+// Drain обрабатывает одну пачку и возвращает, сколько сообщений было взято
+func (d *OutboxDispatcher) Drain() (int, error) {
+	msgs, err := d.repo.Pending(d.batchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	for i := range msgs {
+		msg := &msgs[i]
+
+		if err := d.publisher.Publish(msg.Event); err != nil {
+			msg.Attempts++
+			msg.LastError = err.Error()
+
+			if err := d.repo.MarkFailed(msg, time.Now().Add(d.retryDelay(msg.Attempts))); err != nil {
+				return len(msgs), err
+			}
+			continue
+		}
+
+		if err := d.repo.MarkProcessed(msg.ID); err != nil {
+			return len(msgs), err
+		}
+	}
+
+	return len(msgs), nil
+}
+
+func (d *OutboxDispatcher) retryDelay(attempts int) time.Duration {
+	delay := d.backoff << min(attempts-1, 16)
+	return min(delay, maxOutboxRetryDelay)
+}
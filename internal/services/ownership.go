@@ -0,0 +1,132 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"path"
+	"pr-reviewer/internal/domain"
+	"pr-reviewer/internal/repository"
+	"regexp"
+	"strings"
+)
+
+type OwnershipService interface {
+	CreateRule(rule *domain.OwnershipRule) error
+	ListRules() ([]domain.OwnershipRule, error)
+	DeleteRule(ruleID int64) error
+}
+
+type ownershipService struct {
+	repo  repository.OwnershipRepository
+	users repository.UserRepository
+}
+
+func NewOwnershipService(r repository.OwnershipRepository, ur repository.UserRepository) OwnershipService {
+	return &ownershipService{repo: r, users: ur}
+}
+
+func (s *ownershipService) CreateRule(rule *domain.OwnershipRule) error {
+	if (rule.UserID == "") == (rule.TeamName == "") {
+		return domain.ErrInvalidRule
+	}
+	if _, err := compilePattern(rule.Pattern); err != nil {
+		return domain.ErrInvalidRule
+	}
+
+	if rule.UserID != "" {
+		if _, _, err := s.users.GetById(rule.UserID); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return domain.ErrNotFound
+			}
+			return err
+		}
+	}
+
+	return s.repo.Create(rule)
+}
+
+func (s *ownershipService) ListRules() ([]domain.OwnershipRule, error) {
+	return s.repo.List()
+}
+
+func (s *ownershipService) DeleteRule(ruleID int64) error {
+	return s.repo.Delete(ruleID)
+}
+
+// MatchOwnershipRules возвращает правила, которые владеют хотя бы одним файлом.
+// Для каждого файла, как в CODEOWNERS, действует последнее совпавшее правило.
+// Результат — в порядке rules
+func MatchOwnershipRules(rules []domain.OwnershipRule, files []string) []domain.OwnershipRule {
+	compiled := make([]*regexp.Regexp, len(rules))
+	for i, rule := range rules {
+		compiled[i], _ = compilePattern(rule.Pattern)
+	}
+
+	owning := make(map[int]bool)
+	for _, file := range files {
+		file = strings.TrimPrefix(path.Clean("/"+file), "/")
+		for i := len(rules) - 1; i >= 0; i-- {
+			if compiled[i] != nil && compiled[i].MatchString(file) {
+				owning[i] = true
+				break
+			}
+		}
+	}
+
+	var matched []domain.OwnershipRule
+	for i, rule := range rules {
+		if owning[i] {
+			matched = append(matched, rule)
+		}
+	}
+
+	return matched
+}
+
+// compilePattern переводит шаблон CODEOWNERS в regexp:
+//   - "*.go" (без "/") совпадает с файлом в любом каталоге;
+//   - "docs/" (без "/" в середине) — всё внутри каталога docs на любой глубине, как "*.go";
+//   - "/docs/" или "src/docs/" ("/" в начале или середине) — только от корня;
+//   - "*" — любой сегмент без "/", "**" — любое число сегментов, "?" — один символ
+func compilePattern(pattern string) (*regexp.Regexp, error) {
+	pattern = strings.TrimSpace(pattern)
+	if pattern == "" {
+		return nil, domain.ErrInvalidRule
+	}
+
+	anchored := strings.Contains(strings.TrimSuffix(pattern, "/"), "/")
+	dir := strings.HasSuffix(pattern, "/")
+	pattern = strings.Trim(pattern, "/")
+
+	var b strings.Builder
+	if anchored {
+		b.WriteString("^")
+	} else {
+		b.WriteString("(^|/)")
+	}
+
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; c {
+		case '*':
+			if i+1 < len(pattern) && pattern[i+1] == '*' {
+				b.WriteString(".*")
+				i++
+			} else {
+				b.WriteString("[^/]*")
+			}
+		case '?':
+			b.WriteString("[^/]")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+
+	// шаблон совпадает и с самим путём, и со всем, что лежит под ним как под каталогом
+	if dir {
+		b.WriteString("/.*$")
+	} else {
+		b.WriteString("(/.*)?$")
+	}
+
+	return regexp.Compile(b.String())
+}
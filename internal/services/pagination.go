@@ -0,0 +1,98 @@
+package services
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"pr-reviewer/internal/domain"
+	"slices"
+	"time"
+)
+
+// EncodeCursor — непрозрачный next_cursor для клиента; запоминает сортировку выдачи
+func EncodeCursor(c *domain.PageCursor, sort string) *string {
+	if c == nil {
+		return nil
+	}
+
+	withSort := *c
+	withSort.Sort = sort
+
+	raw, err := json.Marshal(withSort)
+	if err != nil {
+		return nil
+	}
+	cursor := base64.RawURLEncoding.EncodeToString(raw)
+	return &cursor
+}
+
+// DecodeCursor — nil для пустой строки, ErrInvalidCursor для испорченной
+// или выданной для другой сортировки (продолжение дало бы пропуски и дубли)
+func DecodeCursor(cursor, sort string) (*domain.PageCursor, error) {
+	if cursor == "" {
+		return nil, nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, domain.ErrInvalidCursor
+	}
+
+	var c domain.PageCursor
+	if err := json.Unmarshal(raw, &c); err != nil || c.ID == "" || c.CreatedAt.IsZero() || c.Sort != sort {
+		return nil, domain.ErrInvalidCursor
+	}
+	return &c, nil
+}
+
+// курсор выдачи, отсортированной только по id (пользователи)
+func encodeIDCursor(id string) *string {
+	if id == "" {
+		return nil
+	}
+	cursor := base64.RawURLEncoding.EncodeToString([]byte(id))
+	return &cursor
+}
+
+func decodeIDCursor(cursor string) (string, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return "", domain.ErrInvalidCursor
+	}
+	return string(raw), nil
+}
+
+// normalizePage подставляет сортировку и лимит по умолчанию и проверяет их
+func normalizePage(sort *string, limit *int) error {
+	if *sort == "" {
+		*sort = domain.SortCreatedDesc
+	}
+	if *sort != domain.SortCreatedDesc && *sort != domain.SortCreatedAsc {
+		return domain.ErrInvalidFilter
+	}
+
+	return normalizeLimit(limit)
+}
+
+func normalizeLimit(limit *int) error {
+	if *limit == 0 {
+		*limit = domain.DefaultPageLimit
+	}
+	if *limit < 0 || *limit > domain.MaxPageLimit {
+		return domain.ErrInvalidFilter
+	}
+	return nil
+}
+
+func validStatuses(statuses []string) bool {
+	for _, s := range statuses {
+		if !slices.Contains([]string{domain.StatusDraft, domain.StatusOpen, domain.StatusMerged, domain.StatusClosed}, s) {
+			return false
+		}
+	}
+	return true
+}
+
+// validRange — from раньше to, если заданы обе границы
+func validRange(from, to *time.Time) bool {
+	return from == nil || to == nil || from.Before(*to)
+}
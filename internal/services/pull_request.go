@@ -10,22 +10,47 @@ import (
 )
 
 type PullRequestService interface {
-	Create(pr *domain.PullRequest) (*domain.PullRequest, error)
-	Merge(prID string) (*domain.PullRequest, error)
-	Reassign(prID, oldReviewerID string) (*domain.PullRequest, string, error)
-	GetReview(userID string) ([]domain.PullRequestShort, error)
+	Create(pr *domain.PullRequest, actor string) (*domain.PullRequest, error)
+	Merge(prID string, force bool, actor string) (*domain.PullRequest, error)
+	Reassign(prID, oldReviewerID, newReviewerID, actor string) (*domain.PullRequest, string, error)
+	AddReviewer(prID, userID, actor string) (*domain.PullRequest, error)
+	RemoveReviewer(prID, userID string, force bool, actor string) (*domain.PullRequest, error)
+	GetReview(filter *domain.ReviewFilter) (*domain.PullRequestPage, error)
+	List(filter *domain.PullRequestFilter) (*domain.PullRequestListPage, error)
+	Get(prID string) (*domain.PullRequest, error)
+	Review(review *domain.Review) (*domain.PullRequest, error)
+	Close(prID, actor string) (*domain.PullRequest, error)
+	Reopen(prID, actor string) (*domain.PullRequest, error)
+	Ready(prID, actor string) (*domain.PullRequest, error)
+	ReassignAll(userID, actor string) (*domain.ReassignReport, error)
+	ReassignTeam(userID string, teamID int64, actor string) (*domain.ReassignReport, error)
+	History(prID string) ([]domain.Event, error)
 }
 
+// actor во всех методах — кто выполняет действие (для журнала событий), может быть пустым
+
 type pullRequestService struct {
-	repo  repository.PullRequestRepository
-	users repository.UserRepository // get author(user) by id
+	repo      repository.PullRequestRepository
+	users     repository.UserRepository // get author(user) by id
+	teams     repository.TeamRepository // team settings
+	owners    repository.OwnershipRepository
+	events    repository.EventRepository // чтение журнала; запись — через repo.AddEvents в транзакции изменения
+	selectors *ReviewerSelectors
+	requester ReviewRequester // nil — интеграция с code host выключена
 }
 
-func NewPullRequestService(r repository.PullRequestRepository, ur repository.UserRepository) PullRequestService {
-	return &pullRequestService{repo: r, users: ur}
+func NewPullRequestService(r repository.PullRequestRepository, ur repository.UserRepository, tr repository.TeamRepository, or repository.OwnershipRepository, er repository.EventRepository, sel *ReviewerSelectors, rr ReviewRequester) PullRequestService {
+	return &pullRequestService{repo: r, users: ur, teams: tr, owners: or, events: er, selectors: sel, requester: rr}
 }
 
-func (s *pullRequestService) Create(pr *domain.PullRequest) (*domain.PullRequest, error) {
+// requestReviewers просит code host запросить ревью у назначенных пользователей (в фоне)
+func (s *pullRequestService) requestReviewers(prID string, userIDs ...string) {
+	if s.requester != nil {
+		s.requester.RequestReviewers(prID, userIDs)
+	}
+}
+
+func (s *pullRequestService) Create(pr *domain.PullRequest, actor string) (*domain.PullRequest, error) {
 
 	exists, err := s.repo.Exists(pr.ID)
 	if err != nil {
@@ -35,33 +60,73 @@ func (s *pullRequestService) Create(pr *domain.PullRequest) (*domain.PullRequest
 		return nil, domain.ErrPRExists
 	}
 
-	author, _, err := s.users.GetById(pr.AuthorID)
+	author, teamName, err := s.users.GetById(pr.AuthorID)
 	if err != nil {
 		log.Println(err)
 		return nil, domain.ErrNotFound
 	}
 
-	reviewers, err := s.repo.GetTeamMembers(author.TeamID, author.ID)
+	// ревьюверы назначаются из выбранной команды, по умолчанию — из основной команды автора
+	if pr.TeamName != "" {
+		team, err := s.teams.Get(pr.TeamName)
+		if err != nil {
+			return nil, err
+		}
+		pr.TeamID = team.ID
+	} else {
+		pr.TeamID, pr.TeamName = author.TeamID, teamName
+	}
+
+	// черновик: ревьюверы назначаются, когда PR станет ready
+	if pr.Status == domain.StatusDraft {
+		pr.AssignedReviewers = []domain.Reviewer{}
+
+		err := s.repo.WithTx(func(tx repository.PullRequestRepository) error {
+			if err := tx.Create(pr); err != nil {
+				return err
+			}
+			return tx.AddEvents(prEvent(pr.ID, actor, domain.EventPRCreated, map[string]any{"status": pr.Status}))
+		})
+		if err != nil {
+			log.Println(err)
+			return nil, err
+		}
+
+		return pr, nil
+	}
+
+	reviewers, warnings, err := s.selectReviewers(pr)
 	if err != nil {
 		return nil, err
 	}
 
 	pr.Status = domain.StatusOpen
 	pr.AssignedReviewers = reviewers
+	pr.Warnings = warnings
 
-	if err := s.repo.Create(pr); err != nil {
+	err = s.repo.WithTx(func(tx repository.PullRequestRepository) error {
+		if err := tx.Create(pr); err != nil {
+			return err
+		}
+		if err := tx.AssignReviewers(pr.ID, reviewers); err != nil {
+			return err
+		}
+		return tx.AddEvents(
+			prEvent(pr.ID, actor, domain.EventPRCreated, map[string]any{"status": pr.Status}),
+			assignedEvent(pr, actor),
+		)
+	})
+	if err != nil {
 		log.Println(err)
 		return nil, err
 	}
 
-	if err := s.repo.AssignReviewers(pr.ID, reviewers); err != nil {
-		return nil, err
-	}
+	s.requestReviewers(pr.ID, reviewerIDs(reviewers)...)
 
 	return pr, nil
 }
 
-func (s *pullRequestService) Merge(prID string) (*domain.PullRequest, error) {
+func (s *pullRequestService) Merge(prID string, force bool, actor string) (*domain.PullRequest, error) {
 
 	pr, err := s.repo.GetByID(prID)
 	if err != nil {
@@ -73,87 +138,601 @@ func (s *pullRequestService) Merge(prID string) (*domain.PullRequest, error) {
 		return pr, nil
 	}
 
+	if err := checkNotDraftOrClosed(pr); err != nil {
+		return nil, err
+	}
+
+	if !force {
+		if err := s.checkMergeGate(pr); err != nil {
+			return nil, err
+		}
+	}
+
 	now := time.Now().UTC().Format(time.RFC3339)
 
-	if err := s.repo.Merge(prID, now); err != nil {
+	err = s.repo.WithTx(func(tx repository.PullRequestRepository) error {
+		if err := tx.Merge(prID, now, force); err != nil {
+			return err
+		}
+		return tx.AddEvents(prEvent(prID, actor, domain.EventPRMerged, map[string]any{"force": force}))
+	})
+	if err != nil {
 		return nil, err
 	}
 
 	pr.Status = domain.StatusMerged
 	pr.MergedAt = &now
+	pr.ForceMerged = force
 
 	return pr, nil
 }
 
-func (s *pullRequestService) Reassign(prID, oldReviewerID string) (*domain.PullRequest, string, error) {
+// checkMergeGate: нужно required_approvals целевой команды и ни одного актуального CHANGES_REQUESTED.
+// Если ревьюверов меньше required_approvals (не назначены или сняты), merge возможен только с force
+func (s *pullRequestService) checkMergeGate(pr *domain.PullRequest) error {
+	if err := s.targetTeam(pr); err != nil {
+		return err
+	}
+
+	settings, err := s.teams.GetSettings(pr.TeamID)
+	if err != nil {
+		return err
+	}
+
+	blocked := &domain.MergeBlockedError{
+		Required: settings.RequiredApprovals,
+	}
+
+	for _, r := range pr.AssignedReviewers {
+		switch r.Verdict {
+		case domain.VerdictApproved:
+			blocked.Approved++
+		case domain.VerdictChangesRequested:
+			blocked.ChangesRequested = append(blocked.ChangesRequested, r.UserID)
+			blocked.Pending = append(blocked.Pending, r.UserID)
+		default:
+			blocked.Pending = append(blocked.Pending, r.UserID)
+		}
+	}
+
+	if blocked.Approved < blocked.Required || len(blocked.ChangesRequested) > 0 {
+		return blocked
+	}
+
+	return nil
+}
+
+// targetTeam подставляет основную команду автора, если у PR нет команды
+// (PR создан до появления team_name или его команду удалили)
+func (s *pullRequestService) targetTeam(pr *domain.PullRequest) error {
+	if pr.TeamID != 0 {
+		return nil
+	}
+
+	author, teamName, err := s.users.GetById(pr.AuthorID)
+	if err != nil {
+		return domain.ErrNotFound
+	}
+
+	pr.TeamID, pr.TeamName = author.TeamID, teamName
+	return nil
+}
+
+// Reassign заменяет ревьювера: на newReviewerID, если он задан, иначе на кандидата из целевой команды
+func (s *pullRequestService) Reassign(prID, oldReviewerID, newReviewerID, actor string) (*domain.PullRequest, string, error) {
 
 	pr, err := s.repo.GetByID(prID)
 	if err != nil {
 		return nil, "", domain.ErrNotFound
 	}
 
-	// если PR уже merged то операция запрещена
-	if pr.Status == domain.StatusMerged {
-		return nil, "", domain.ErrPRMerged
+	// если PR уже merged или закрыт то операция запрещена
+	if err := checkReviewersMutable(pr); err != nil {
+		return nil, "", err
 	}
 
 	// проверяем что старый ревьювер назначен
-	found := slices.Contains(pr.AssignedReviewers, oldReviewerID)
+	found := slices.Contains(pr.ReviewerIDs(), oldReviewerID)
 	if !found {
 		return nil, "", domain.ErrNotAssigned
 	}
 
-	
-	// прошлый ревьювер
-	oldReviewer, _, err := s.users.GetById(oldReviewerID)
+	if err := s.targetTeam(pr); err != nil {
+		return nil, "", err
+	}
+
+	settings, err := s.teams.GetSettings(pr.TeamID)
 	if err != nil {
-		return nil, "", domain.ErrNotFound
+		return nil, "", err
 	}
 
-	// кандидат на замену
-	newReviewerID, err := s.repo.FindReplacement(
-		oldReviewer.TeamID,
-		pr.AuthorID,
-		oldReviewerID,
-		pr.AssignedReviewers, 
-	)
+	// заменяем единственного лида при lead_rule — замена тоже лид целевой команды
+	needLead, leads, err := s.replacingLead(pr, oldReviewerID, settings)
 	if err != nil {
-		if errors.Is(err, domain.ErrNoCandidate) {
-			return nil, "", domain.ErrNoCandidate
-		}
 		return nil, "", err
 	}
 
+	var fallback bool
+	manual := newReviewerID != ""
+	switch {
+	case manual:
+		// явно выбранный ревьювер
+		newReviewer, err := s.checkManualCandidate(pr, newReviewerID)
+		if err != nil {
+			return nil, "", err
+		}
+		if needLead && !slices.Contains(leads, newReviewerID) {
+			return nil, "", domain.ErrLeadRequired
+		}
+		fallback = !newReviewer.InTeam(pr.TeamID)
+	case needLead:
+		newReviewerID, err = s.pickLeadReplacement(pr, oldReviewerID, leads, s.selectors.ForTeam(pr.TeamName, settings))
+		if err != nil {
+			return nil, "", err
+		}
+	default:
+		// кандидат на замену
+		newReviewerID, fallback, err = s.pickReplacement(pr, oldReviewerID, settings, s.selectors.ForTeam(pr.TeamName, settings))
+		if err != nil {
+			return nil, "", err
+		}
+	}
 
-	if err := s.repo.ReplaceReviewer(prID, oldReviewerID, newReviewerID); err != nil {
+	err = s.repo.WithTx(func(tx repository.PullRequestRepository) error {
+		if err := tx.ReplaceReviewer(prID, oldReviewerID, newReviewerID, fallback); err != nil {
+			return err
+		}
+		return tx.AddEvents(prEvent(prID, actor, domain.EventReviewerReassigned, map[string]any{
+			"old_user_id": oldReviewerID,
+			"new_user_id": newReviewerID,
+			"fallback":    fallback,
+			"manual":      manual,
+		}))
+	})
+	if err != nil {
 		return nil, "", err
 	}
 
-
 	for idx, r := range pr.AssignedReviewers {
-		if r == oldReviewerID {
-			pr.AssignedReviewers[idx] = newReviewerID
+		if r.UserID == oldReviewerID {
+			pr.AssignedReviewers[idx] = domain.Reviewer{UserID: newReviewerID, Fallback: fallback}
 			break
 		}
 	}
 
+	s.requestReviewers(prID, newReviewerID)
+
 	return pr, newReviewerID, nil
 }
 
-func (s *pullRequestService) GetReview(userID string) ([]domain.PullRequestShort, error) {
+// AddReviewer вручную добавляет ревьювера к PR
+func (s *pullRequestService) AddReviewer(prID, userID, actor string) (*domain.PullRequest, error) {
 
-	_, _, err := s.users.GetById(userID)
+	pr, err := s.repo.GetByID(prID)
 	if err != nil {
 		return nil, domain.ErrNotFound
 	}
 
-	prs, err := s.repo.GetByReviewer(userID)
+	if err := checkReviewersMutable(pr); err != nil {
+		return nil, err
+	}
+	// у черновика ревьюверов нет до ready
+	if pr.Status == domain.StatusDraft {
+		return nil, domain.ErrPRDraft
+	}
+
+	reviewer, err := s.checkManualCandidate(pr, userID)
 	if err != nil {
-		if errors.Is(err, domain.ErrNotFound) {
-			return nil, domain.ErrNotFound
+		return nil, err
+	}
+
+	if err := s.targetTeam(pr); err != nil {
+		return nil, err
+	}
+
+	added := domain.Reviewer{UserID: userID, Fallback: !reviewer.InTeam(pr.TeamID)}
+
+	err = s.repo.WithTx(func(tx repository.PullRequestRepository) error {
+		if err := tx.AssignReviewers(prID, []domain.Reviewer{added}); err != nil {
+			return err
 		}
+		return tx.AddEvents(prEvent(prID, actor, domain.EventReviewersAssigned, map[string]any{"reviewers": []domain.Reviewer{added}, "manual": true}))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	pr.AssignedReviewers = append(pr.AssignedReviewers, added)
+
+	return pr, nil
+}
+
+// RemoveReviewer снимает ревьювера с PR без замены. Ниже min_reviewers команды — только с force
+func (s *pullRequestService) RemoveReviewer(prID, userID string, force bool, actor string) (*domain.PullRequest, error) {
+
+	pr, err := s.repo.GetByID(prID)
+	if err != nil {
+		return nil, domain.ErrNotFound
+	}
+
+	if err := checkReviewersMutable(pr); err != nil {
+		return nil, err
+	}
+
+	if !slices.Contains(pr.ReviewerIDs(), userID) {
+		return nil, domain.ErrNotAssigned
+	}
+
+	if !force {
+		if err := s.targetTeam(pr); err != nil {
+			return nil, err
+		}
+
+		settings, err := s.teams.GetSettings(pr.TeamID)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(pr.AssignedReviewers)-1 < settings.MinReviewers {
+			return nil, domain.ErrNotEnoughReviewers
+		}
+	}
+
+	err = s.repo.WithTx(func(tx repository.PullRequestRepository) error {
+		if err := tx.RemoveReviewer(prID, userID); err != nil {
+			return err
+		}
+		return tx.AddEvents(prEvent(prID, actor, domain.EventReviewerRemoved, map[string]any{"user_id": userID, "force": force}))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	pr.AssignedReviewers = slices.DeleteFunc(pr.AssignedReviewers, func(r domain.Reviewer) bool {
+		return r.UserID == userID
+	})
+
+	return pr, nil
+}
+
+// checkReviewersMutable: после MERGED или CLOSED состав ревьюверов не меняется
+func checkReviewersMutable(pr *domain.PullRequest) error {
+	switch pr.Status {
+	case domain.StatusMerged:
+		return domain.ErrPRMerged
+	case domain.StatusClosed:
+		return domain.ErrPRClosed
+	}
+	return nil
+}
+
+// checkManualCandidate проверяет явно выбранного ревьювера: существует, активен, доступен,
+// не автор и ещё не назначен. Лимиты и стратегия команды при ручном выборе не применяются
+func (s *pullRequestService) checkManualCandidate(pr *domain.PullRequest, userID string) (*domain.User, error) {
+	if userID == pr.AuthorID {
+		return nil, domain.ErrSelfReview
+	}
+
+	user, _, err := s.users.GetById(userID)
+	if err != nil {
+		return nil, domain.ErrNotFound
+	}
+
+	if !user.IsActive {
+		return nil, domain.ErrUserInactive
+	}
+
+	if slices.Contains(pr.ReviewerIDs(), userID) {
+		return nil, domain.ErrAlreadyAssigned
+	}
+
+	// отпуск и другие периоды недоступности — как при автоподборе
+	available, err := s.users.IsAvailable(userID)
+	if err != nil {
 		return nil, err
 	}
+	if !available {
+		return nil, domain.ErrUserUnavailable
+	}
+
+	return user, nil
+}
+
+// ReassignAll переназначает все OPEN ревью пользователя по правилам Reassign
+func (s *pullRequestService) ReassignAll(userID, actor string) (*domain.ReassignReport, error) {
+	return s.reassignOpen(userID, 0, actor)
+}
+
+// ReassignTeam переназначает OPEN ревью пользователя только в PR команды teamID
+func (s *pullRequestService) ReassignTeam(userID string, teamID int64, actor string) (*domain.ReassignReport, error) {
+	return s.reassignOpen(userID, teamID, actor)
+}
+
+// reassignOpen при ошибке возвращает частичный отчёт: что уже переназначено и что осталось (Pending)
+func (s *pullRequestService) reassignOpen(userID string, teamID int64, actor string) (*domain.ReassignReport, error) {
+
+	prIDs, err := s.repo.GetOpenIDsByReviewer(userID, teamID)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &domain.ReassignReport{
+		Reassigned:  []domain.ReassignedReview{},
+		NoCandidate: []domain.SkippedReview{},
+	}
+
+	for i, prID := range prIDs {
+		_, newReviewerID, err := s.Reassign(prID, userID, "", actor)
+		if err != nil {
+			if errors.Is(err, domain.ErrNoCandidate) || errors.Is(err, domain.ErrNoCapacity) || errors.Is(err, domain.ErrLeadRequired) {
+				report.NoCandidate = append(report.NoCandidate, domain.SkippedReview{PullRequestID: prID, Reason: err.Error()})
+				continue
+			}
+			// уже выполненные замены остаются, отчёт возвращается вместе с ошибкой
+			report.Pending = prIDs[i:]
+			return report, err
+		}
+
+		report.Reassigned = append(report.Reassigned, domain.ReassignedReview{
+			PullRequestID: prID,
+			ReplacedBy:    newReviewerID,
+		})
+	}
+
+	return report, nil
+}
+
+// GetReview — страница PR, где пользователь назначен ревьювером; без ревью — пустой список
+func (s *pullRequestService) GetReview(filter *domain.ReviewFilter) (*domain.PullRequestPage, error) {
+
+	if err := normalizePage(&filter.Sort, &filter.Limit); err != nil {
+		return nil, err
+	}
+	if !validStatuses(filter.Statuses) {
+		return nil, domain.ErrInvalidFilter
+	}
+	if !validRange(filter.CreatedAfter, filter.CreatedBefore) {
+		return nil, domain.ErrInvalidFilter
+	}
+
+	after, err := DecodeCursor(filter.Cursor, filter.Sort)
+	if err != nil {
+		return nil, err
+	}
+
+	_, _, err = s.users.GetById(filter.UserID)
+	if err != nil {
+		return nil, domain.ErrNotFound
+	}
+
+	prs, next, err := s.repo.GetByReviewer(filter, after)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.PullRequestPage{PullRequests: prs, NextCursor: EncodeCursor(next, filter.Sort)}, nil
+}
+
+// Get — PR со всеми ревьюверами и их последними вердиктами
+func (s *pullRequestService) Get(prID string) (*domain.PullRequest, error) {
+	return s.repo.GetByID(prID)
+}
+
+// List — поиск PR по фильтру; несуществующие автор, команда или ревьювер дают пустой список
+func (s *pullRequestService) List(filter *domain.PullRequestFilter) (*domain.PullRequestListPage, error) {
+
+	if err := normalizePage(&filter.Sort, &filter.Limit); err != nil {
+		return nil, err
+	}
+	if !validStatuses(filter.Statuses) || !validRange(filter.CreatedAfter, filter.CreatedBefore) || !validRange(filter.MergedAfter, filter.MergedBefore) {
+		return nil, domain.ErrInvalidFilter
+	}
+
+	after, err := DecodeCursor(filter.Cursor, filter.Sort)
+	if err != nil {
+		return nil, err
+	}
+
+	prs, next, err := s.repo.List(filter, after)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.PullRequestListPage{PullRequests: prs, NextCursor: EncodeCursor(next, filter.Sort)}, nil
+}
+
+func (s *pullRequestService) Review(review *domain.Review) (*domain.PullRequest, error) {
+
+	if !domain.IsValidVerdict(review.Verdict) {
+		return nil, domain.ErrInvalidVerdict
+	}
+
+	pr, err := s.repo.GetByID(review.PullRequestID)
+	if err != nil {
+		return nil, err
+	}
+
+	if pr.Status == domain.StatusMerged {
+		return nil, domain.ErrPRMerged
+	}
+	if pr.Status == domain.StatusClosed {
+		return nil, domain.ErrPRClosed
+	}
+
+	// вердикт может оставить только назначенный ревьювер
+	if !slices.Contains(pr.ReviewerIDs(), review.ReviewerID) {
+		return nil, domain.ErrNotAssigned
+	}
+
+	err = s.repo.WithTx(func(tx repository.PullRequestRepository) error {
+		if err := tx.AddReview(review); err != nil {
+			return err
+		}
+		return tx.AddEvents(prEvent(pr.ID, review.ReviewerID, domain.EventReviewSubmitted, map[string]any{"review_id": review.ID, "verdict": review.Verdict}))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for idx, r := range pr.AssignedReviewers {
+		if r.UserID == review.ReviewerID {
+			pr.AssignedReviewers[idx].Verdict = review.Verdict
+			pr.AssignedReviewers[idx].VerdictAt = &review.CreatedAt
+			break
+		}
+	}
+
+	return pr, nil
+}
+
+func checkNotDraftOrClosed(pr *domain.PullRequest) error {
+	switch pr.Status {
+	case domain.StatusDraft:
+		return domain.ErrPRDraft
+	case domain.StatusClosed:
+		return domain.ErrPRClosed
+	}
+	return nil
+}
+
+// Close закрывает PR без merge (идемпотентно)
+func (s *pullRequestService) Close(prID, actor string) (*domain.PullRequest, error) {
+
+	pr, err := s.repo.GetByID(prID)
+	if err != nil {
+		return nil, err
+	}
+
+	switch pr.Status {
+	case domain.StatusClosed:
+		return pr, nil
+	case domain.StatusMerged:
+		return nil, domain.ErrPRMerged
+	}
+
+	now := time.Now().UTC().Format(time.RFC3339)
+
+	err = s.repo.WithTx(func(tx repository.PullRequestRepository) error {
+		if err := tx.SetStatus(prID, pr.Status, domain.StatusClosed, &now); err != nil {
+			return err
+		}
+		return tx.AddEvents(prEvent(prID, actor, domain.EventPRClosed, nil))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	pr.Status, pr.ClosedFrom = domain.StatusClosed, pr.Status
+	pr.ClosedAt = &now
+
+	return pr, nil
+}
+
+// Reopen возвращает закрытый PR в статус до закрытия: OPEN или DRAFT (идемпотентно)
+func (s *pullRequestService) Reopen(prID, actor string) (*domain.PullRequest, error) {
+
+	pr, err := s.repo.GetByID(prID)
+	if err != nil {
+		return nil, err
+	}
+
+	switch pr.Status {
+	case domain.StatusOpen, domain.StatusDraft:
+		return pr, nil
+	case domain.StatusMerged:
+		return nil, domain.ErrPRMerged
+	}
+
+	// PR закрыли черновиком — остаётся черновиком, ревьюверы назначатся в ready
+	if pr.ClosedFrom == domain.StatusDraft {
+		err = s.repo.WithTx(func(tx repository.PullRequestRepository) error {
+			if err := tx.SetStatus(prID, domain.StatusClosed, domain.StatusDraft, nil); err != nil {
+				return err
+			}
+			return tx.AddEvents(prEvent(prID, actor, domain.EventPRReopened, map[string]any{"status": domain.StatusDraft}))
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		pr.Status, pr.ClosedFrom = domain.StatusDraft, ""
+		pr.ClosedAt = nil
+
+		return pr, nil
+	}
+
+	// PR, закрытый до учёта closed_from, без ревьюверов мог быть черновиком — назначаем
+	if err := s.open(pr, actor, domain.EventPRReopened, len(pr.AssignedReviewers) == 0); err != nil {
+		return nil, err
+	}
+
+	return pr, nil
+}
+
+// Ready переводит DRAFT в OPEN и назначает ревьюверов (идемпотентно)
+func (s *pullRequestService) Ready(prID, actor string) (*domain.PullRequest, error) {
+
+	pr, err := s.repo.GetByID(prID)
+	if err != nil {
+		return nil, err
+	}
+
+	switch pr.Status {
+	case domain.StatusOpen:
+		return pr, nil
+	case domain.StatusMerged:
+		return nil, domain.ErrPRMerged
+	case domain.StatusClosed:
+		return nil, domain.ErrPRClosed
+	}
+
+	if err := s.open(pr, actor, domain.EventPRReady, true); err != nil {
+		return nil, err
+	}
+
+	return pr, nil
+}
+
+// open переводит PR в OPEN; при assign подбирает ревьюверов и назначает их в той же транзакции
+func (s *pullRequestService) open(pr *domain.PullRequest, actor, eventType string, assign bool) error {
+	events := []domain.Event{prEvent(pr.ID, actor, eventType, nil)}
+
+	if assign {
+		if err := s.targetTeam(pr); err != nil {
+			return err
+		}
+
+		reviewers, warnings, err := s.selectReviewers(pr)
+		if err != nil {
+			return err
+		}
+
+		pr.AssignedReviewers = reviewers
+		pr.Warnings = warnings
+		events = append(events, assignedEvent(pr, actor))
+	}
+
+	err := s.repo.WithTx(func(tx repository.PullRequestRepository) error {
+		if assign {
+			if err := tx.AssignReviewers(pr.ID, pr.AssignedReviewers); err != nil {
+				return err
+			}
+		}
+		if err := tx.SetStatus(pr.ID, pr.Status, domain.StatusOpen, nil); err != nil {
+			return err
+		}
+		return tx.AddEvents(events...)
+	})
+	if err != nil {
+		return err
+	}
+
+	pr.Status, pr.ClosedFrom = domain.StatusOpen, ""
+	pr.ClosedAt = nil
+
+	if assign {
+		s.requestReviewers(pr.ID, reviewerIDs(pr.AssignedReviewers)...)
+	}
 
-	return prs, nil
+	return nil
 }
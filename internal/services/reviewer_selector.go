@@ -0,0 +1,178 @@
+package services
+
+import (
+	"log"
+	"math/rand/v2"
+	"pr-reviewer/internal/domain"
+	"pr-reviewer/internal/repository"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const (
+	StrategyRandom      = "random"
+	StrategyRoundRobin  = "round_robin"
+	StrategyLeastLoaded = "least_loaded"
+)
+
+// ReviewerSelector выбирает до count ревьюверов из списка кандидатов команды
+type ReviewerSelector interface {
+	Select(teamID int64, candidates []string, count int) ([]string, error)
+}
+
+func IsKnownStrategy(name string) bool {
+	switch name {
+	case StrategyRandom, StrategyRoundRobin, StrategyLeastLoaded:
+		return true
+	}
+	return false
+}
+
+// RANDOM
+
+type randomSelector struct{}
+
+func NewRandomSelector() ReviewerSelector {
+	return &randomSelector{}
+}
+
+func (s *randomSelector) Select(_ int64, candidates []string, count int) ([]string, error) {
+	if len(candidates) == 0 || count <= 0 {
+		return nil, nil
+	}
+
+	shuffled := slices.Clone(candidates)
+	rand.Shuffle(len(shuffled), func(i, j int) {
+		shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
+	})
+
+	return shuffled[:min(count, len(shuffled))], nil
+}
+
+// ROUND ROBIN
+
+type roundRobinSelector struct {
+	mu   sync.Mutex
+	last map[int64]string // последний выбранный ревьювер по команде
+}
+
+func NewRoundRobinSelector() ReviewerSelector {
+	return &roundRobinSelector{last: make(map[int64]string)}
+}
+
+func (s *roundRobinSelector) Select(teamID int64, candidates []string, count int) ([]string, error) {
+	if len(candidates) == 0 || count <= 0 {
+		return nil, nil
+	}
+
+	sorted := slices.Clone(candidates)
+	slices.Sort(sorted)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// начинаем со следующего после последнего выбранного,
+	// так очередь не сбивается при изменении состава команды
+	start := 0
+	if last, ok := s.last[teamID]; ok {
+		start = sort.SearchStrings(sorted, last)
+		if start < len(sorted) && sorted[start] == last {
+			start++
+		}
+	}
+
+	n := min(count, len(sorted))
+	selected := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		selected = append(selected, sorted[(start+i)%len(sorted)])
+	}
+
+	s.last[teamID] = selected[len(selected)-1]
+
+	return selected, nil
+}
+
+// LEAST LOADED
+
+type leastLoadedSelector struct {
+	repo repository.PullRequestRepository
+}
+
+func NewLeastLoadedSelector(r repository.PullRequestRepository) ReviewerSelector {
+	return &leastLoadedSelector{repo: r}
+}
+
+func (s *leastLoadedSelector) Select(_ int64, candidates []string, count int) ([]string, error) {
+	if len(candidates) == 0 || count <= 0 {
+		return nil, nil
+	}
+
+	load, err := s.repo.GetOpenReviewLoad(candidates)
+	if err != nil {
+		return nil, err
+	}
+
+	return RankByLoad(candidates, load)[:min(count, len(candidates))], nil
+}
+
+// RankByLoad сортирует кандидатов по числу открытых ревью,
+// при равной нагрузке — по user_id, чтобы выбор был детерминированным
+func RankByLoad(candidates []string, load map[string]int) []string {
+	ranked := slices.Clone(candidates)
+	slices.SortFunc(ranked, func(a, b string) int {
+		if load[a] != load[b] {
+			return load[a] - load[b]
+		}
+		return strings.Compare(a, b)
+	})
+
+	return ranked
+}
+
+// ReviewerSelectors хранит стратегии и определяет, какую использовать для команды
+type ReviewerSelectors struct {
+	selectors map[string]ReviewerSelector
+	fallback  string
+	teams     map[string]string // team_name -> strategy
+}
+
+func NewReviewerSelectors(r repository.PullRequestRepository, defaultStrategy string, teamStrategies map[string]string) *ReviewerSelectors {
+	if !IsKnownStrategy(defaultStrategy) {
+		log.Printf("unknown reviewer strategy %q, using %q", defaultStrategy, StrategyRandom)
+		defaultStrategy = StrategyRandom
+	}
+
+	teams := make(map[string]string, len(teamStrategies))
+	for team, strategy := range teamStrategies {
+		if !IsKnownStrategy(strategy) {
+			log.Printf("unknown reviewer strategy %q for team %q, skipped", strategy, team)
+			continue
+		}
+		teams[team] = strategy
+	}
+
+	return &ReviewerSelectors{
+		selectors: map[string]ReviewerSelector{
+			StrategyRandom:      NewRandomSelector(),
+			StrategyRoundRobin:  NewRoundRobinSelector(),
+			StrategyLeastLoaded: NewLeastLoadedSelector(r),
+		},
+		fallback: defaultStrategy,
+		teams:    teams,
+	}
+}
+
+// ForTeam: стратегия из настроек команды, затем из конфигурации, затем по умолчанию
+func (s *ReviewerSelectors) ForTeam(teamName string, settings *domain.TeamSettings) ReviewerSelector {
+	if settings != nil {
+		if sel, ok := s.selectors[settings.Strategy]; ok {
+			return sel
+		}
+	}
+	if strategy, ok := s.teams[teamName]; ok {
+		return s.selectors[strategy]
+	}
+	return s.selectors[s.fallback]
+}
@@ -1,25 +1,49 @@
 package services
 
 import (
+	"database/sql"
+	"errors"
 	"pr-reviewer/internal/domain"
 	"pr-reviewer/internal/repository"
+	"regexp"
 )
 
 type TeamService interface {
-	GetTeam(team_name string) (*domain.Team, error)
+	GetTeam(team_name string, withSubteams bool) (*domain.Team, error)
 	CreateTeam(team *domain.Team) error
+	GetSettings(team_name string) (*domain.TeamSettings, error)
+	UpdateSettings(team_name string, update *domain.TeamSettingsUpdate) (*domain.TeamSettings, error)
+	AddMember(team_name string, user *domain.User, primary bool, actor string) (*domain.UserResponse, error)
+	RemoveMember(team_name, userID, actor string) (*domain.ReassignReport, error)
+	Rename(team_name, newName string) (*domain.Team, error)
+	Delete(team_name string) error
+	SetParent(team_name, parent_name string) (*domain.Team, error)
 }
 
 type teamService struct {
-	repo repository.TeamRepository
+	repo       repository.TeamRepository
+	users      repository.UserRepository
+	reassigner ReviewReassigner
 }
 
-func NewTeamService(r repository.TeamRepository) TeamService {
-	return &teamService{repo: r}
+func NewTeamService(r repository.TeamRepository, ur repository.UserRepository, ra ReviewReassigner) TeamService {
+	return &teamService{repo: r, users: ur, reassigner: ra}
 }
 
 func (t *teamService) CreateTeam(team *domain.Team) error {
 
+	if team.Settings != nil {
+		if err := t.validateSettings(team.TeamName, team.Settings); err != nil {
+			return err
+		}
+	}
+
+	for _, member := range team.Members {
+		if !domain.IsValidRole(member.Role) {
+			return domain.ErrInvalidRole
+		}
+	}
+
 	exist, err := t.repo.Exist(team.TeamName)
 	if err != nil {
 		return err
@@ -27,10 +51,349 @@ func (t *teamService) CreateTeam(team *domain.Team) error {
 	if exist {
 		return domain.ErrTeamNameTaken
 	}
+
+	if team.ParentTeam != "" {
+		parent, err := t.repo.Get(team.ParentTeam)
+		if err != nil {
+			return err
+		}
+		team.ParentID = parent.ID
+	}
+
 	return t.repo.Create(team)
 }
 
-func (t *teamService) GetTeam(team_name string) (*domain.Team, error) {
-	return t.repo.Get(team_name)
+// GetTeam с withSubteams возвращает всё поддерево: подкоманды с участниками, вложенные в subteams
+func (t *teamService) GetTeam(team_name string, withSubteams bool) (*domain.Team, error) {
+	team, err := t.repo.Get(team_name)
+	if err != nil || !withSubteams {
+		return team, err
+	}
+
+	subteams, err := t.repo.Subteams(team.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	children := make(map[int64][]domain.Team)
+	for _, sub := range subteams {
+		children[sub.ParentID] = append(children[sub.ParentID], sub)
+	}
+
+	var build func(id int64) []domain.Team
+	build = func(id int64) []domain.Team {
+		kids := children[id]
+		for i := range kids {
+			kids[i].Subteams = build(kids[i].ID)
+		}
+		return kids
+	}
+
+	team.Subteams = build(team.ID)
+	return team, nil
+}
 
+// SetParent переносит команду под parent_name (пусто — на верхний уровень).
+// Родителем не может быть сама команда или её подкоманда
+func (t *teamService) SetParent(team_name, parent_name string) (*domain.Team, error) {
+	team, err := t.repo.Get(team_name)
+	if err != nil {
+		return nil, err
+	}
+
+	var parentID int64
+	if parent_name != "" {
+		parent, err := t.repo.Get(parent_name)
+		if err != nil {
+			return nil, err
+		}
+
+		if parent.ID == team.ID {
+			return nil, domain.ErrInvalidParent
+		}
+
+		subteams, err := t.repo.Subteams(team.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, sub := range subteams {
+			if sub.ID == parent.ID {
+				return nil, domain.ErrInvalidParent
+			}
+		}
+
+		parentID = parent.ID
+	}
+
+	if err := t.repo.SetParent(team.ID, parentID); err != nil {
+		return nil, err
+	}
+
+	team.ParentID, team.ParentTeam = parentID, parent_name
+	return team, nil
+}
+
+func (t *teamService) GetSettings(team_name string) (*domain.TeamSettings, error) {
+	team, err := t.repo.Get(team_name)
+	if err != nil {
+		return nil, err
+	}
+
+	return team.Settings, nil
+}
+
+func (t *teamService) UpdateSettings(team_name string, update *domain.TeamSettingsUpdate) (*domain.TeamSettings, error) {
+	team, err := t.repo.Get(team_name)
+	if err != nil {
+		return nil, err
+	}
+
+	settings := team.Settings
+	if update.ReviewersCount != nil {
+		settings.ReviewersCount = *update.ReviewersCount
+	}
+	if update.MinReviewers != nil {
+		settings.MinReviewers = *update.MinReviewers
+	}
+	if update.RequiredApprovals != nil {
+		settings.RequiredApprovals = *update.RequiredApprovals
+	}
+	if update.Strategy != nil {
+		settings.Strategy = *update.Strategy
+	}
+	if update.FallbackTeams != nil {
+		settings.FallbackTeams = *update.FallbackTeams
+	}
+	if update.Escalation != nil {
+		settings.Escalation = *update.Escalation
+	}
+	if update.LeadRule != nil {
+		settings.LeadRule = *update.LeadRule
+	}
+	if update.LeadTitlePattern != nil {
+		settings.LeadTitlePattern = *update.LeadTitlePattern
+	}
+
+	if err := t.validateSettings(team_name, settings); err != nil {
+		return nil, err
+	}
+
+	if err := t.repo.UpdateSettings(team.ID, settings); err != nil {
+		return nil, err
+	}
+
+	return settings, nil
+}
+
+func (t *teamService) validateSettings(team_name string, s *domain.TeamSettings) error {
+	if s.ReviewersCount < 0 || s.MinReviewers < 0 || s.MinReviewers > s.ReviewersCount || s.RequiredApprovals < 0 {
+		return domain.ErrInvalidSettings
+	}
+	if s.Strategy != "" && !IsKnownStrategy(s.Strategy) {
+		return domain.ErrInvalidSettings
+	}
+	if s.Escalation != "" && !IsKnownEscalation(s.Escalation) {
+		return domain.ErrInvalidSettings
+	}
+	if s.LeadRule != "" && !IsKnownLeadRule(s.LeadRule) {
+		return domain.ErrInvalidSettings
+	}
+	// для title нужен корректный шаблон названия
+	if s.LeadRule == LeadRuleTitle {
+		if s.LeadTitlePattern == "" {
+			return domain.ErrInvalidSettings
+		}
+		if _, err := regexp.Compile(s.LeadTitlePattern); err != nil {
+			return domain.ErrInvalidSettings
+		}
+	}
+
+	// резервные команды: существуют, без повторов и без самой команды
+	seen := make(map[string]bool, len(s.FallbackTeams))
+	for _, name := range s.FallbackTeams {
+		if name == team_name || seen[name] {
+			return domain.ErrInvalidSettings
+		}
+		seen[name] = true
+
+		exist, err := t.repo.Exist(name)
+		if err != nil {
+			return err
+		}
+		if !exist {
+			return domain.ErrInvalidSettings
+		}
+	}
+
+	return nil
+}
+
+// AddMember добавляет нового пользователя (активным) или существующего ещё в одну команду;
+// в прежних командах он остаётся, ревью не переназначаются. Команда становится основной,
+// если primary или у пользователя не было команд. Для участника этой же команды
+// обновляются username, max_open_reviews и роль (если указана)
+func (t *teamService) AddMember(team_name string, user *domain.User, primary bool, actor string) (*domain.UserResponse, error) {
+	if user.MaxOpenReviews != nil && *user.MaxOpenReviews < 0 {
+		return nil, domain.ErrInvalidCapacity
+	}
+	if !domain.IsValidRole(user.Role) {
+		return nil, domain.ErrInvalidRole
+	}
+
+	team, err := t.repo.Get(team_name)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, primaryTeam, err := t.users.GetById(user.ID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	resp := &domain.UserResponse{UserID: user.ID, UserName: user.UserName, TeamName: team.TeamName, IsActive: true, MaxOpenReviews: user.MaxOpenReviews}
+
+	var events []domain.Event
+
+	switch {
+	case existing == nil:
+		events = append(events, t.teamChangedEvent(user.ID, actor, team.TeamName, "", team.TeamName))
+
+	case existing.TeamID == 0:
+		// исключённый пользователь возвращается активным
+		events = append(events,
+			t.teamChangedEvent(user.ID, actor, team.TeamName, "", team.TeamName),
+			domain.Event{UserID: user.ID, Actor: actor, Type: domain.EventUserActivated},
+		)
+
+	default:
+		resp.IsActive = existing.IsActive
+
+		joined := ""
+		if !existing.InTeam(team.ID) {
+			joined = team.TeamName
+		}
+
+		newPrimary := ""
+		if primary && existing.TeamID != team.ID {
+			newPrimary = team.TeamName
+		} else {
+			resp.TeamName = primaryTeam
+		}
+
+		if joined != "" || newPrimary != "" {
+			events = append(events, t.teamChangedEvent(user.ID, actor, joined, "", newPrimary))
+		}
+	}
+
+	if err := t.repo.AddMember(team.ID, user, primary, events...); err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// RemoveMember исключает пользователя из команды: его OPEN ревью в PR этой команды переназначаются.
+// Если команда была последней, переназначаются все ревью, пользователь деактивируется
+// и больше не назначается ревьювером. PR, где он автор, и история остаются.
+// Членство снимается до переназначения, чтобы пользователя не подобрали заменой в той же команде;
+// если переназначение прервалось, возвращается частичный отчёт (не nil) вместе с ошибкой
+func (t *teamService) RemoveMember(team_name, userID, actor string) (*domain.ReassignReport, error) {
+	team, err := t.repo.Get(team_name)
+	if err != nil {
+		return nil, err
+	}
+
+	user, _, err := t.users.GetById(userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	if !user.InTeam(team.ID) {
+		return nil, domain.ErrNotFound
+	}
+
+	last := len(user.TeamIDs) == 1
+
+	events := []domain.Event{t.teamChangedEvent(userID, actor, "", team.TeamName, "")}
+	if last && user.IsActive {
+		events = append(events, domain.Event{UserID: userID, Actor: actor, Type: domain.EventUserDeactivated})
+	}
+
+	if err := t.repo.RemoveMember(team.ID, userID, events...); err != nil {
+		return nil, err
+	}
+
+	var report *domain.ReassignReport
+	if last {
+		report, err = t.reassigner.ReassignAll(userID, actor)
+	} else {
+		report, err = t.reassigner.ReassignTeam(userID, team.ID, actor)
+	}
+	if err != nil && report == nil {
+		// не удалось даже получить список ревью — заменено ничего не было
+		report = &domain.ReassignReport{Reassigned: []domain.ReassignedReview{}, NoCandidate: []domain.SkippedReview{}}
+	}
+
+	return report, err
+}
+
+func (t *teamService) Rename(team_name, newName string) (*domain.Team, error) {
+	team, err := t.repo.Get(team_name)
+	if err != nil {
+		return nil, err
+	}
+
+	if newName == team_name {
+		return team, nil
+	}
+
+	exist, err := t.repo.Exist(newName)
+	if err != nil {
+		return nil, err
+	}
+	if exist {
+		return nil, domain.ErrTeamNameTaken
+	}
+
+	if err := t.repo.Rename(team.ID, newName); err != nil {
+		return nil, err
+	}
+
+	team.TeamName = newName
+	return team, nil
+}
+
+// Delete удаляет только пустую команду: участников нужно сначала исключить, подкоманды — перенести
+func (t *teamService) Delete(team_name string) error {
+	team, err := t.repo.Get(team_name)
+	if err != nil {
+		return err
+	}
+
+	if len(team.Members) > 0 {
+		return domain.ErrTeamNotEmpty
+	}
+
+	subteams, err := t.repo.Subteams(team.ID)
+	if err != nil {
+		return err
+	}
+	if len(subteams) > 0 {
+		return domain.ErrTeamNotEmpty
+	}
+
+	return t.repo.Delete(team.ID)
+}
+
+// teamChangedEvent — в payload только изменившееся: joined_team, left_team, primary_team
+func (t *teamService) teamChangedEvent(userID, actor, joined, left, primary string) domain.Event {
+	payload := map[string]any{}
+	for key, team := range map[string]string{"joined_team": joined, "left_team": left, "primary_team": primary} {
+		if team != "" {
+			payload[key] = team
+		}
+	}
+	return newEvent(domain.Event{UserID: userID, Actor: actor, Type: domain.EventUserTeamChanged}, payload)
 }
@@ -5,21 +5,96 @@ import (
 	"errors"
 	"pr-reviewer/internal/domain"
 	"pr-reviewer/internal/repository"
+	"strings"
+	"unicode/utf8"
 )
 
+// users.username VARCHAR(50)
+const maxUsernameLength = 50
+
 type UserService interface {
-	SetIsActive(userId string, value bool) (*domain.UserResponse, error)
+	SetIsActive(userId string, value bool, actor string) (*domain.UserResponse, *domain.ReassignReport, error)
+	SetMaxOpenReviews(userId string, value *int) (*domain.UserResponse, error)
+	AddUnavailability(period *domain.Unavailability) error
+	ListUnavailability(userId string) ([]domain.Unavailability, error)
+	DeleteUnavailability(id int64) error
+	Get(userId string) (*domain.UserResponse, error)
+	List(filter *domain.UserFilter) (*domain.UserPage, error)
+	UpdateUsername(userId, username string) (*domain.UserResponse, error)
+}
+
+// ReviewReassigner снимает ревью с пользователя (реализуется PullRequestService)
+type ReviewReassigner interface {
+	ReassignAll(userID, actor string) (*domain.ReassignReport, error)
+	ReassignTeam(userID string, teamID int64, actor string) (*domain.ReassignReport, error)
 }
 
 type userService struct {
-	userRepo repository.UserRepository
+	userRepo   repository.UserRepository
+	reassigner ReviewReassigner
 }
 
-func NewUserService(r repository.UserRepository) UserService {
-	return &userService{userRepo: r}
+func NewUserService(r repository.UserRepository, ra ReviewReassigner) UserService {
+	return &userService{userRepo: r, reassigner: ra}
+}
+
+// SetIsActive при деактивации переназначает открытые ревью пользователя. Флаг сохраняется до
+// переназначения, чтобы пользователя не назначили заново; если переназначение прервалось ошибкой,
+// возвращаются пользователь и частичный отчёт вместе с ошибкой. Повторная деактивация флаг
+// не меняет, но заново переназначает оставшиеся ревью — так дочищается прерванный раньше отчёт
+func (s *userService) SetIsActive(userId string, value bool, actor string) (*domain.UserResponse, *domain.ReassignReport, error) {
+	user, teamName, err := s.userRepo.GetById(userId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil, domain.ErrNotFound
+		}
+		return nil, nil, err
+	}
+
+	if value && user.IsActive {
+		return nil, nil, domain.ErrAlreadyInState
+	}
+
+	if user.IsActive != value {
+		eventType := domain.EventUserDeactivated
+		if value {
+			eventType = domain.EventUserActivated
+		}
+
+		event := domain.Event{UserID: userId, Actor: actor, Type: eventType}
+		if err := s.userRepo.SetIsActive(userId, value, event); err != nil {
+			return nil, nil, err
+		}
+
+		user.IsActive = value
+	}
+
+	resp := &domain.UserResponse{
+		UserID:   user.ID,
+		UserName: user.UserName,
+		TeamName: teamName,
+		IsActive: user.IsActive,
+
+		MaxOpenReviews: user.MaxOpenReviews,
+	}
+
+	if value {
+		return resp, nil, nil
+	}
+
+	report, err := s.reassigner.ReassignAll(userId, actor)
+	if err != nil {
+		return resp, report, err
+	}
+
+	return resp, report, nil
 }
 
-func (s *userService) SetIsActive(userId string, value bool) (*domain.UserResponse, error) {
+func (s *userService) SetMaxOpenReviews(userId string, value *int) (*domain.UserResponse, error) {
+	if value != nil && *value < 0 {
+		return nil, domain.ErrInvalidCapacity
+	}
+
 	user, teamName, err := s.userRepo.GetById(userId)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -28,20 +103,102 @@ func (s *userService) SetIsActive(userId string, value bool) (*domain.UserRespon
 		return nil, err
 	}
 
-	if user.IsActive == value {
-		return nil, domain.ErrAlreadyInState
+	if err := s.userRepo.SetMaxOpenReviews(userId, value); err != nil {
+		return nil, err
+	}
+
+	return &domain.UserResponse{
+		UserID:         user.ID,
+		UserName:       user.UserName,
+		TeamName:       teamName,
+		IsActive:       user.IsActive,
+		MaxOpenReviews: value,
+	}, nil
+}
+
+func (s *userService) AddUnavailability(period *domain.Unavailability) error {
+	if !period.EndsAt.After(period.StartsAt) {
+		return domain.ErrInvalidPeriod
+	}
+
+	if _, _, err := s.userRepo.GetById(period.UserID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.ErrNotFound
+		}
+		return err
 	}
 
-	if err := s.userRepo.SetIsActive(userId, value); err != nil {
+	return s.userRepo.AddUnavailability(period)
+}
+
+func (s *userService) ListUnavailability(userId string) ([]domain.Unavailability, error) {
+	if _, _, err := s.userRepo.GetById(userId); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrNotFound
+		}
 		return nil, err
 	}
 
-	user.IsActive = value
+	return s.userRepo.ListUnavailability(userId)
+}
+
+func (s *userService) DeleteUnavailability(id int64) error {
+	return s.userRepo.DeleteUnavailability(id)
+}
+
+func (s *userService) Get(userId string) (*domain.UserResponse, error) {
+	user, teamName, err := s.userRepo.GetById(userId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
 
 	return &domain.UserResponse{
-		UserID:   user.ID,
-		UserName: user.UserName,
-		TeamName: teamName,
-		IsActive: user.IsActive,
+		UserID:         user.ID,
+		UserName:       user.UserName,
+		TeamName:       teamName,
+		IsActive:       user.IsActive,
+		MaxOpenReviews: user.MaxOpenReviews,
 	}, nil
 }
+
+// List — пользователи по user_id; несуществующая команда даёт пустой список
+func (s *userService) List(filter *domain.UserFilter) (*domain.UserPage, error) {
+	if err := normalizeLimit(&filter.Limit); err != nil {
+		return nil, err
+	}
+
+	afterID, err := decodeIDCursor(filter.Cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	users, next, err := s.userRepo.List(filter, afterID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.UserPage{Users: users, NextCursor: encodeIDCursor(next)}, nil
+}
+
+// UpdateUsername сохраняет имя без крайних пробелов; длина проверяется в символах, а не байтах
+func (s *userService) UpdateUsername(userId, username string) (*domain.UserResponse, error) {
+	username = strings.TrimSpace(username)
+	if n := utf8.RuneCountInString(username); n == 0 || n > maxUsernameLength {
+		return nil, domain.ErrInvalidUsername
+	}
+
+	user, err := s.Get(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.userRepo.SetUsername(userId, username); err != nil {
+		return nil, err
+	}
+
+	user.UserName = username
+	return user, nil
+}
@@ -0,0 +1,266 @@
+package services
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"pr-reviewer/internal/domain"
+	"pr-reviewer/internal/repository"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+)
+
+type WebhookService interface {
+	Create(hook *domain.Webhook) error
+	List() ([]domain.Webhook, error)
+	Delete(id int64) error
+	Deliveries(webhookID int64, status string) ([]domain.WebhookDelivery, error)
+}
+
+type webhookService struct {
+	repo repository.WebhookRepository
+}
+
+func NewWebhookService(r repository.WebhookRepository) WebhookService {
+	return &webhookService{repo: r}
+}
+
+func (s *webhookService) Create(hook *domain.Webhook) error {
+	u, err := url.Parse(hook.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return domain.ErrInvalidWebhook
+	}
+	if hook.Secret == "" {
+		return domain.ErrInvalidWebhook
+	}
+	for _, e := range hook.Events {
+		if !domain.IsKnownEventType(e) {
+			return domain.ErrInvalidWebhook
+		}
+	}
+
+	if err := s.repo.Create(hook); err != nil {
+		return err
+	}
+
+	hook.Secret = ""
+	return nil
+}
+
+// List не возвращает секреты подписок
+func (s *webhookService) List() ([]domain.Webhook, error) {
+	hooks, err := s.repo.List()
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range hooks {
+		hooks[i].Secret = ""
+	}
+	return hooks, nil
+}
+
+func (s *webhookService) Delete(id int64) error {
+	return s.repo.Delete(id)
+}
+
+func (s *webhookService) Deliveries(webhookID int64, status string) ([]domain.WebhookDelivery, error) {
+	if status != "" && !slices.Contains([]string{domain.DeliveryPending, domain.DeliverySuccess, domain.DeliveryFailed}, status) {
+		return nil, domain.ErrInvalidWebhook
+	}
+
+	return s.repo.ListDeliveries(webhookID, status)
+}
+
+// WebhookDispatcher рассылает события подписчикам: подписанный HMAC-SHA256 JSON,
+// повтор при ошибке с экспоненциальной задержкой. Доставка остаётся PENDING, пока
+// попытки не закончились, и после рестарта продолжается через Resume
+type WebhookDispatcher struct {
+	repo        repository.WebhookRepository
+	client      *http.Client
+	maxAttempts int
+	backoff     time.Duration // задержка перед второй попыткой, дальше удваивается
+
+	wg       sync.WaitGroup
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+func NewWebhookDispatcher(r repository.WebhookRepository, client *http.Client, maxAttempts int, backoff time.Duration) *WebhookDispatcher {
+	return &WebhookDispatcher{repo: r, client: client, maxAttempts: max(maxAttempts, 1), backoff: backoff, stop: make(chan struct{})}
+}
+
+// Publish создаёт доставки для подходящих подписок и отправляет их в фоне.
+// Ошибка — не все доставки созданы, outbox передаст событие повторно
+// (получатели должны быть готовы к дублям, event_id в теле)
+func (d *WebhookDispatcher) Publish(event domain.Event) error {
+	hooks, err := d.repo.List()
+	if err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("marshal webhook body: %w", err)
+	}
+
+	for _, hook := range hooks {
+		if !hook.Accepts(event.Type) {
+			continue
+		}
+
+		delivery := &domain.WebhookDelivery{
+			WebhookID: hook.ID,
+			EventID:   event.ID,
+			EventType: event.Type,
+			Status:    domain.DeliveryPending,
+		}
+		if err := d.repo.CreateDelivery(delivery); err != nil {
+			return err
+		}
+
+		d.start(hook, delivery, body)
+	}
+
+	return nil
+}
+
+// Resume продолжает доставки, оставшиеся PENDING после остановки процесса.
+// Вызывается при старте до запуска outbox, чтобы не отправлять одну доставку дважды параллельно
+func (d *WebhookDispatcher) Resume() error {
+	pending, err := d.repo.PendingDeliveries()
+	if err != nil {
+		return err
+	}
+
+	for i := range pending {
+		p := &pending[i]
+
+		// лимит попыток мог уменьшиться между запусками
+		if p.Delivery.Attempts >= d.maxAttempts {
+			p.Delivery.Status = domain.DeliveryFailed
+			if err := d.repo.UpdateDelivery(&p.Delivery); err != nil {
+				return err
+			}
+			continue
+		}
+
+		body, err := json.Marshal(p.Event)
+		if err != nil {
+			return fmt.Errorf("marshal webhook body: %w", err)
+		}
+		d.start(p.Webhook, &p.Delivery, body)
+	}
+
+	if len(pending) > 0 {
+		log.Printf("webhooks: resumed %d pending deliveries", len(pending))
+	}
+	return nil
+}
+
+func (d *WebhookDispatcher) start(hook domain.Webhook, delivery *domain.WebhookDelivery, body []byte) {
+	d.wg.Add(1)
+	go func() {
+		defer d.wg.Done()
+		d.deliver(hook, delivery, body)
+	}()
+}
+
+// Wait ждёт завершения начатых доставок
+func (d *WebhookDispatcher) Wait() {
+	d.wg.Wait()
+}
+
+// Shutdown прерывает ожидание повторов и ждёт текущие попытки.
+// Недоставленное остаётся PENDING и будет продолжено Resume при следующем запуске
+func (d *WebhookDispatcher) Shutdown() {
+	d.stopOnce.Do(func() { close(d.stop) })
+	d.wg.Wait()
+}
+
+func (d *WebhookDispatcher) deliver(hook domain.Webhook, delivery *domain.WebhookDelivery, body []byte) {
+	// после рестарта задержка продолжает расти с учётом прошлых попыток
+	delay := d.backoff << max(delivery.Attempts-1, 0)
+
+	for delivery.Attempts < d.maxAttempts {
+		if delivery.Attempts > 0 {
+			select {
+			case <-d.stop:
+				return
+			case <-time.After(delay):
+			}
+			delay *= 2
+		}
+
+		delivery.Attempts++
+		code, err := d.send(hook, delivery, body)
+
+		delivery.ResponseCode = nil
+		if code != 0 {
+			delivery.ResponseCode = &code
+		}
+
+		switch {
+		case err == nil:
+			delivery.Status = domain.DeliverySuccess
+			delivery.LastError = ""
+		case delivery.Attempts >= d.maxAttempts:
+			delivery.Status = domain.DeliveryFailed
+			delivery.LastError = err.Error()
+		default:
+			delivery.LastError = err.Error()
+		}
+
+		if uerr := d.repo.UpdateDelivery(delivery); uerr != nil {
+			log.Println("webhook delivery update:", uerr)
+		}
+
+		if err == nil {
+			return
+		}
+	}
+}
+
+// send возвращает код ответа (0, если ответа нет); ошибка — при любом не-2xx
+func (d *WebhookDispatcher) send(hook domain.Webhook, delivery *domain.WebhookDelivery, body []byte) (int, error) {
+	req, err := http.NewRequest(http.MethodPost, hook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Event-Type", delivery.EventType)
+	req.Header.Set("X-Delivery-Id", strconv.FormatInt(delivery.ID, 10))
+	req.Header.Set("X-Signature-256", SignPayload(hook.Secret, body))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		if cerr := resp.Body.Close(); cerr != nil {
+			log.Println("webhook response close:", cerr)
+		}
+	}()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	return resp.StatusCode, nil
+}
+
+// SignPayload — значение заголовка X-Signature-256: "sha256=" + hex(HMAC-SHA256(secret, body))
+func SignPayload(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
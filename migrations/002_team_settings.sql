@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS team_settings (
+    team_id INT PRIMARY KEY REFERENCES teams(team_id) ON DELETE CASCADE,
+    reviewers_count INT NOT NULL DEFAULT 2 CHECK (reviewers_count >= 0),
+    min_reviewers INT NOT NULL DEFAULT 0 CHECK (min_reviewers >= 0),
+    strategy VARCHAR(20),
+    CHECK (min_reviewers <= reviewers_count)
+);
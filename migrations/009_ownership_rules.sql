@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS ownership_rules (
+    rule_id SERIAL PRIMARY KEY,
+    pattern VARCHAR(200) NOT NULL,
+    user_id VARCHAR(50) REFERENCES users(user_id) ON DELETE CASCADE,
+    team_id INT REFERENCES teams(team_id) ON DELETE CASCADE,
+    CHECK ((user_id IS NULL) <> (team_id IS NULL))
+);
+
+ALTER TABLE pull_requests ADD COLUMN IF NOT EXISTS changed_files TEXT[] NOT NULL DEFAULT '{}';
+
+ALTER TABLE reviewers ADD COLUMN IF NOT EXISTS owner_rule VARCHAR(200);
@@ -0,0 +1,12 @@
+-- журнал событий; без внешних ключей, чтобы история переживала удаление PR и пользователей
+CREATE TABLE IF NOT EXISTS events (
+    event_id BIGSERIAL PRIMARY KEY,
+    pull_request_id VARCHAR(50),
+    user_id VARCHAR(50),
+    actor VARCHAR(50),
+    event_type VARCHAR(50) NOT NULL,
+    payload JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS events_pull_request_idx ON events (pull_request_id, event_id);
@@ -0,0 +1,9 @@
+-- логины на GitHub/GitLab -> user_id сервиса
+CREATE TABLE IF NOT EXISTS user_identities (
+    provider VARCHAR(20) NOT NULL CHECK (provider IN ('github', 'gitlab')),
+    login VARCHAR(100) NOT NULL,
+    user_id VARCHAR(50) NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    PRIMARY KEY (provider, login)
+);
+
+CREATE INDEX IF NOT EXISTS user_identities_user_idx ON user_identities (user_id);
@@ -0,0 +1,8 @@
+-- PR сервиса -> PR на code host, куда отправляются запросы ревью.
+-- Без внешнего ключа: связь пишется до создания PR (см. IntegrationService.Apply)
+CREATE TABLE IF NOT EXISTS code_host_pull_requests (
+    pull_request_id VARCHAR(50) PRIMARY KEY,
+    provider VARCHAR(20) NOT NULL CHECK (provider IN ('github', 'gitlab')),
+    repository VARCHAR(255) NOT NULL,
+    number BIGINT NOT NULL
+);
@@ -0,0 +1,3 @@
+-- выборка PR ревьювера (/users/getReview) с сортировкой по created_at
+CREATE INDEX IF NOT EXISTS reviewers_user_idx ON reviewers (user_id);
+CREATE INDEX IF NOT EXISTS pull_requests_created_idx ON pull_requests (created_at, pull_request_id);
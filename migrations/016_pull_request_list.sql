@@ -0,0 +1,2 @@
+-- фильтры /pullRequest/list по автору (и команде автора)
+CREATE INDEX IF NOT EXISTS pull_requests_author_idx ON pull_requests (author, created_at);
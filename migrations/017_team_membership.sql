@@ -0,0 +1,7 @@
+-- пользователь может быть исключён из команды (team_id NULL), его PR и история остаются.
+-- Команду с участниками удалить нельзя: каскад удалил бы пользователей вместе с их PR
+ALTER TABLE users ALTER COLUMN team_id DROP NOT NULL;
+
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_team_id_fkey;
+ALTER TABLE users ADD CONSTRAINT users_team_id_fkey
+    FOREIGN KEY (team_id) REFERENCES teams(team_id) ON DELETE RESTRICT;
@@ -0,0 +1,34 @@
+-- пользователь может состоять в нескольких командах; users.team_id — основная команда
+-- (NULL — пользователь исключён из всех команд)
+CREATE TABLE IF NOT EXISTS team_memberships (
+    user_id VARCHAR(50) NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    team_id INT NOT NULL REFERENCES teams(team_id) ON DELETE RESTRICT,
+    PRIMARY KEY (user_id, team_id)
+);
+
+CREATE INDEX IF NOT EXISTS team_memberships_team_idx ON team_memberships (team_id, user_id);
+
+INSERT INTO team_memberships (user_id, team_id)
+SELECT user_id, team_id FROM users WHERE team_id IS NOT NULL
+ON CONFLICT DO NOTHING;
+
+-- команда, из которой назначаются ревьюверы PR; для старых PR — основная команда автора.
+-- Заполняется один раз при добавлении колонки: миграции применяются при каждом старте,
+-- а NULL после удаления команды (ON DELETE SET NULL) перезаписывать нельзя
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1 FROM information_schema.columns
+        WHERE table_schema = current_schema() AND table_name = 'pull_requests' AND column_name = 'team_id'
+    ) THEN
+        ALTER TABLE pull_requests ADD COLUMN team_id INT REFERENCES teams(team_id) ON DELETE SET NULL;
+
+        UPDATE pull_requests pr
+        SET team_id = u.team_id
+        FROM users u
+        WHERE u.user_id = pr.author AND u.team_id IS NOT NULL;
+    END IF;
+END
+$$;
+
+CREATE INDEX IF NOT EXISTS pull_requests_team_idx ON pull_requests (team_id, created_at);
@@ -0,0 +1,10 @@
+-- иерархия команд (отдел -> группа -> команда); удалить команду с подкомандами нельзя
+ALTER TABLE teams ADD COLUMN IF NOT EXISTS parent_id INT REFERENCES teams(team_id) ON DELETE RESTRICT;
+
+CREATE INDEX IF NOT EXISTS teams_parent_idx ON teams (parent_id);
+
+-- куда искать замену ревьювера, если в команде и резервных командах никого нет; NULL — никуда
+ALTER TABLE team_settings ADD COLUMN IF NOT EXISTS escalation VARCHAR(20);
+ALTER TABLE team_settings DROP CONSTRAINT IF EXISTS team_settings_escalation_check;
+ALTER TABLE team_settings ADD CONSTRAINT team_settings_escalation_check
+    CHECK (escalation IS NULL OR escalation IN ('none', 'siblings', 'parent', 'siblings_parent'));
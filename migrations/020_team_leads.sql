@@ -0,0 +1,12 @@
+-- роль участника в команде: лид или обычный участник
+ALTER TABLE team_memberships ADD COLUMN IF NOT EXISTS role VARCHAR(10) NOT NULL DEFAULT 'member';
+ALTER TABLE team_memberships DROP CONSTRAINT IF EXISTS team_memberships_role_check;
+ALTER TABLE team_memberships ADD CONSTRAINT team_memberships_role_check
+    CHECK (role IN ('member', 'lead'));
+
+-- обязательный лид среди ревьюверов: NULL — не требуется, always, title (по шаблону названия PR)
+ALTER TABLE team_settings ADD COLUMN IF NOT EXISTS lead_rule VARCHAR(10);
+ALTER TABLE team_settings ADD COLUMN IF NOT EXISTS lead_title_pattern TEXT;
+ALTER TABLE team_settings DROP CONSTRAINT IF EXISTS team_settings_lead_rule_check;
+ALTER TABLE team_settings ADD CONSTRAINT team_settings_lead_rule_check
+    CHECK (lead_rule IS NULL OR lead_rule IN ('none', 'always', 'title'));
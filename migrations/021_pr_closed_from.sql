@@ -0,0 +1,2 @@
+-- статус, из которого PR закрыли (DRAFT или OPEN): reopen возвращает черновик в DRAFT
+ALTER TABLE pull_requests ADD COLUMN IF NOT EXISTS closed_from VARCHAR(10);
@@ -0,0 +1,59 @@
+package tests
+
+import (
+	"pr-reviewer/internal/domain"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// участники на лимите max_open_reviews не попадают в GetTeamMembers, их число даёт CountAtCapacity
+
+func TestCreate_SkipsCandidatesAtCapacityWithWarning(t *testing.T) {
+	repo, teams, svc := createFixture(&domain.TeamSettings{ReviewersCount: 2, MinReviewers: 1})
+	teams.On("GetFallbackTeamIDs", int64(1)).Return(nil, nil)
+	repo.On("GetTeamMembers", int64(1), "u1").Return([]string{"u2"}, nil)
+	repo.On("CountAtCapacity", []int64{1}, []string{"u2", "u1"}).Return(1, nil)
+
+	pr, err := svc.Create(&domain.PullRequest{ID: "pr-1", Name: "x", AuthorID: "u1"}, "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"u2"}, pr.ReviewerIDs())
+	require.Len(t, pr.Warnings, 1)
+	assert.Equal(t, domain.ErrNoCapacity.Error(), pr.Warnings[0].Code)
+	assert.Equal(t, "assigned 1 of 2 reviewers: 1 candidates reached max_open_reviews", pr.Warnings[0].Message)
+}
+
+func TestCreate_NoWarningWhenTeamIsSmall(t *testing.T) {
+	// ревьюверов меньше из-за размера команды, а не лимита — без предупреждения
+	repo, teams, svc := createFixture(&domain.TeamSettings{ReviewersCount: 2, MinReviewers: 1})
+	teams.On("GetFallbackTeamIDs", int64(1)).Return(nil, nil)
+	repo.On("GetTeamMembers", int64(1), "u1").Return([]string{"u2"}, nil)
+	repo.On("CountAtCapacity", []int64{1}, []string{"u2", "u1"}).Return(0, nil)
+
+	pr, err := svc.Create(&domain.PullRequest{ID: "pr-1", Name: "x", AuthorID: "u1"}, "")
+	require.NoError(t, err)
+	assert.Empty(t, pr.Warnings)
+}
+
+func TestCreate_NoCapacityBelowMinReviewers(t *testing.T) {
+	repo, teams, svc := createFixture(&domain.TeamSettings{ReviewersCount: 2, MinReviewers: 2})
+	teams.On("GetFallbackTeamIDs", int64(1)).Return([]int64{2}, nil)
+	repo.On("GetTeamMembers", int64(1), "u1").Return([]string{"u2"}, nil)
+	repo.On("GetTeamMembers", int64(2), "u1").Return([]string{}, nil)
+	repo.On("CountAtCapacity", []int64{1, 2}, []string{"u2", "u1"}).Return(3, nil)
+
+	_, err := svc.Create(&domain.PullRequest{ID: "pr-1", Name: "x", AuthorID: "u1"}, "")
+	assert.ErrorIs(t, err, domain.ErrNoCapacity)
+	repo.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+func TestReassign_NoCapacity(t *testing.T) {
+	repo, _, svc := reassignFixture("")
+	repo.On("CountAtCapacity", []int64{2}, []string{"r1", "u1"}).Return(2, nil)
+
+	_, _, err := svc.Reassign("pr-1", "r1", "", "")
+	assert.ErrorIs(t, err, domain.ErrNoCapacity)
+	repo.AssertNotCalled(t, "ReplaceReviewer", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
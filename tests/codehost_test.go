@@ -0,0 +1,149 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"pr-reviewer/internal/domain"
+	"pr-reviewer/internal/services"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGitHubClient_RequestReviewers(t *testing.T) {
+	var gotPath, gotAuth string
+	var gotBody map[string][]string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotAuth = r.Header.Get("Authorization")
+		_ = json.NewDecoder(r.Body).Decode(&gotBody)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	client := services.NewGitHubClient(server.URL, "ghp_test", server.Client())
+	err := client.RequestReviewers(context.Background(), &domain.CodeHostPullRequest{
+		Provider: domain.ProviderGitHub, Repository: "acme/pr-reviewer", Number: 42,
+	}, []string{"bob-gh"})
+
+	require.NoError(t, err)
+	assert.Equal(t, "/repos/acme/pr-reviewer/pulls/42/requested_reviewers", gotPath)
+	assert.Equal(t, "Bearer ghp_test", gotAuth)
+	assert.Equal(t, []string{"bob-gh"}, gotBody["reviewers"])
+}
+
+func TestGitHubClient_ErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		_, _ = w.Write([]byte(`{"message":"Reviews may only be requested from collaborators."}`))
+	}))
+	defer server.Close()
+
+	client := services.NewGitHubClient(server.URL, "ghp_test", server.Client())
+	err := client.RequestReviewers(context.Background(), &domain.CodeHostPullRequest{Repository: "acme/x", Number: 1}, []string{"bob-gh"})
+
+	assert.ErrorContains(t, err, "status 422")
+}
+
+func TestCodeHostReviewRequester_Retries(t *testing.T) {
+	link := &domain.CodeHostPullRequest{PullRequestID: "github-1", Provider: domain.ProviderGitHub, Repository: "acme/x", Number: 5}
+
+	links := new(MockCodeHostRepository)
+	links.On("GetLink", "github-1").Return(link, nil)
+	links.On("Logins", domain.ProviderGitHub, []string{"u2", "u3"}).Return([]string{"bob-gh"}, nil)
+
+	fake := &services.FakeCodeHostClient{FailTimes: 2}
+	requester := services.NewCodeHostReviewRequester(links, map[string]services.CodeHostClient{domain.ProviderGitHub: fake}, 3, time.Millisecond, time.Second)
+
+	requester.RequestReviewers("github-1", []string{"u2", "u3"})
+	requester.Wait()
+
+	assert.Equal(t, []services.FakeReviewRequest{{PullRequest: *link, Logins: []string{"bob-gh"}}}, fake.Requests())
+}
+
+func TestCodeHostReviewRequester_SkipsUnlinkedPR(t *testing.T) {
+	links := new(MockCodeHostRepository)
+	links.On("GetLink", "pr-1").Return(nil, domain.ErrNotFound)
+
+	fake := &services.FakeCodeHostClient{}
+	requester := services.NewCodeHostReviewRequester(links, map[string]services.CodeHostClient{domain.ProviderGitHub: fake}, 3, time.Millisecond, time.Second)
+
+	requester.RequestReviewers("pr-1", []string{"u2"})
+	requester.Wait()
+
+	assert.Empty(t, fake.Requests())
+	links.AssertNumberOfCalls(t, "GetLink", 1)
+}
+
+func githubRequester(t *testing.T, statuses ...int) (*services.CodeHostReviewRequester, *atomic.Int32) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// статусы по порядку попыток, дальше — успех
+		n := int(calls.Add(1))
+		if n <= len(statuses) {
+			w.WriteHeader(statuses[n-1])
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	t.Cleanup(server.Close)
+
+	link := &domain.CodeHostPullRequest{PullRequestID: "github-1", Provider: domain.ProviderGitHub, Repository: "acme/x", Number: 5}
+	links := new(MockCodeHostRepository)
+	links.On("GetLink", "github-1").Return(link, nil)
+	links.On("Logins", domain.ProviderGitHub, []string{"u2"}).Return([]string{"bob-gh"}, nil)
+
+	client := services.NewGitHubClient(server.URL, "ghp_test", server.Client())
+	return services.NewCodeHostReviewRequester(links, map[string]services.CodeHostClient{domain.ProviderGitHub: client}, 3, time.Millisecond, time.Second), &calls
+}
+
+func TestCodeHostReviewRequester_RetriesTemporaryStatus(t *testing.T) {
+	for _, status := range []int{http.StatusBadGateway, http.StatusTooManyRequests} {
+		requester, calls := githubRequester(t, status)
+
+		requester.RequestReviewers("github-1", []string{"u2"})
+		requester.Wait()
+
+		assert.Equal(t, int32(2), calls.Load(), status)
+	}
+}
+
+func TestCodeHostReviewRequester_NoRetryOnClientError(t *testing.T) {
+	for _, status := range []int{http.StatusUnprocessableEntity, http.StatusNotFound, http.StatusForbidden} {
+		requester, calls := githubRequester(t, status)
+
+		requester.RequestReviewers("github-1", []string{"u2"})
+		requester.Wait()
+
+		assert.Equal(t, int32(1), calls.Load(), status)
+	}
+}
+
+func TestCodeHostReviewRequester_ShutdownSkipsBackoff(t *testing.T) {
+	links := new(MockCodeHostRepository)
+	links.On("GetLink", "github-1").Return(&domain.CodeHostPullRequest{PullRequestID: "github-1", Provider: domain.ProviderGitHub}, nil)
+	links.On("Logins", domain.ProviderGitHub, []string{"u2"}).Return([]string{"bob-gh"}, nil)
+
+	fake := &services.FakeCodeHostClient{FailTimes: 1}
+	requester := services.NewCodeHostReviewRequester(links, map[string]services.CodeHostClient{domain.ProviderGitHub: fake}, 3, time.Hour, time.Second)
+	requester.RequestReviewers("github-1", []string{"u2"})
+
+	done := make(chan struct{})
+	go func() {
+		requester.Shutdown()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("shutdown waits for retry backoff")
+	}
+	assert.Empty(t, fake.Requests())
+}
@@ -0,0 +1,20 @@
+package tests
+
+import (
+	config "pr-reviewer/configs"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoad_NonPositiveDurationsFallBackToDefaults(t *testing.T) {
+	t.Setenv("OUTBOX_INTERVAL", "0s")
+	t.Setenv("WEBHOOK_BACKOFF", "-1s")
+	t.Setenv("CODE_HOST_TIMEOUT", "3s")
+
+	conf := config.Load()
+	assert.Equal(t, time.Second, conf.OutboxInterval)
+	assert.Equal(t, time.Second, conf.WebhookBackoff)
+	assert.Equal(t, 3*time.Second, conf.CodeHostTimeout)
+}
@@ -0,0 +1,111 @@
+package tests
+
+import (
+	"errors"
+	"pr-reviewer/internal/domain"
+	"pr-reviewer/internal/services"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockEventRepository struct {
+	mock.Mock
+}
+
+func (m *MockEventRepository) ListByPullRequest(prID string) ([]domain.Event, error) {
+	args := m.Called(prID)
+	events, _ := args.Get(0).([]domain.Event)
+	return events, args.Error(1)
+}
+
+func historyFixture() (*MockPullRequestRepository, *MockEventRepository, services.PullRequestService) {
+	repo := new(MockPullRequestRepository)
+	events := new(MockEventRepository)
+	return repo, events, services.NewPullRequestService(repo, new(MockUserRepository), new(MockTeamRepository), nil, events, nil, nil)
+}
+
+func TestHistory_ReturnsEvents(t *testing.T) {
+	repo, events, svc := historyFixture()
+	journal := []domain.Event{
+		{ID: 1, PullRequestID: "pr-1", Actor: "u1", Type: domain.EventPRCreated},
+		{ID: 2, PullRequestID: "pr-1", Actor: "u1", Type: domain.EventPRMerged},
+	}
+	events.On("ListByPullRequest", "pr-1").Return(journal, nil)
+
+	got, err := svc.History("pr-1")
+	require.NoError(t, err)
+	assert.Equal(t, journal, got)
+	// журнал не пуст — существование PR не проверяется, история доступна и после удаления
+	repo.AssertNotCalled(t, "Exists", mock.Anything)
+}
+
+func TestHistory_EmptyForExistingPR(t *testing.T) {
+	repo, events, svc := historyFixture()
+	events.On("ListByPullRequest", "pr-1").Return([]domain.Event{}, nil)
+	repo.On("Exists", "pr-1").Return(true, nil)
+
+	got, err := svc.History("pr-1")
+	require.NoError(t, err)
+	assert.Empty(t, got)
+}
+
+func TestHistory_UnknownPR(t *testing.T) {
+	repo, events, svc := historyFixture()
+	events.On("ListByPullRequest", "pr-9").Return([]domain.Event{}, nil)
+	repo.On("Exists", "pr-9").Return(false, nil)
+
+	_, err := svc.History("pr-9")
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+}
+
+func TestHistory_ListError(t *testing.T) {
+	_, events, svc := historyFixture()
+	events.On("ListByPullRequest", "pr-1").Return(nil, errors.New("connection reset"))
+
+	_, err := svc.History("pr-1")
+	assert.Error(t, err)
+}
+
+func TestEvents_EmittedWithActorAndPayload(t *testing.T) {
+	repo, teams, svc := createFixture(&domain.TeamSettings{ReviewersCount: 1, MinReviewers: 1})
+	teams.On("GetFallbackTeamIDs", int64(1)).Return(nil, nil)
+	repo.On("GetTeamMembers", int64(1), "u1").Return([]string{"u2"}, nil)
+
+	pr, err := svc.Create(&domain.PullRequest{ID: "pr-1", Name: "x", AuthorID: "u1"}, "u1")
+	require.NoError(t, err)
+
+	// дальше тот же PR читается из репозитория
+	repo.On("GetByID", "pr-1").Return(pr, nil)
+	repo.On("FindReplacement", int64(1), "u1", "u2", []string{"u2"}).Return([]string{"u3"}, nil)
+	repo.On("ReplaceReviewer", "pr-1", "u2", "u3", false).Return(nil)
+	repo.On("Merge", "pr-1", mock.Anything, true).Return(nil)
+
+	_, _, err = svc.Reassign("pr-1", "u2", "", "lead")
+	require.NoError(t, err)
+	_, err = svc.Merge("pr-1", true, "admin")
+	require.NoError(t, err)
+
+	require.Len(t, repo.events, 4)
+	for _, e := range repo.events {
+		assert.Equal(t, "pr-1", e.PullRequestID)
+	}
+
+	assert.Equal(t, domain.EventPRCreated, repo.events[0].Type)
+	assert.Equal(t, "u1", repo.events[0].Actor)
+	assert.JSONEq(t, `{"status":"OPEN"}`, string(repo.events[0].Payload))
+
+	assert.Equal(t, domain.EventReviewersAssigned, repo.events[1].Type)
+	assert.Equal(t, "u1", repo.events[1].Actor)
+	assert.JSONEq(t, `{"reviewers":[{"user_id":"u2","fallback":false}],"warnings":null}`, string(repo.events[1].Payload))
+
+	assert.Equal(t, domain.EventReviewerReassigned, repo.events[2].Type)
+	assert.Equal(t, "lead", repo.events[2].Actor)
+	assert.JSONEq(t, `{"old_user_id":"u2","new_user_id":"u3","fallback":false,"manual":false}`, string(repo.events[2].Payload))
+
+	assert.Equal(t, domain.EventPRMerged, repo.events[3].Type)
+	assert.Equal(t, "admin", repo.events[3].Actor)
+	assert.JSONEq(t, `{"force":true}`, string(repo.events[3].Payload))
+}
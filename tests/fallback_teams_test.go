@@ -0,0 +1,64 @@
+package tests
+
+import (
+	"pr-reviewer/internal/domain"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreate_FillsFromFallbackTeamsInOrder(t *testing.T) {
+	repo, teams, svc := createFixture(&domain.TeamSettings{ReviewersCount: 3})
+	teams.On("GetFallbackTeamIDs", int64(1)).Return([]int64{2, 3}, nil)
+	repo.On("GetTeamMembers", int64(1), "u1").Return([]string{"u2"}, nil)
+	repo.On("GetTeamMembers", int64(2), "u1").Return([]string{"f1"}, nil)
+	repo.On("GetTeamMembers", int64(3), "u1").Return([]string{"g1"}, nil)
+
+	pr, err := svc.Create(&domain.PullRequest{ID: "pr-1", Name: "x", AuthorID: "u1"}, "")
+	require.NoError(t, err)
+	assert.Equal(t, []domain.Reviewer{
+		{UserID: "u2"},
+		{UserID: "f1", Fallback: true},
+		{UserID: "g1", Fallback: true},
+	}, pr.AssignedReviewers)
+}
+
+func TestCreate_FallbackStopsWhenFilled(t *testing.T) {
+	repo, teams, svc := createFixture(&domain.TeamSettings{ReviewersCount: 3})
+	teams.On("GetFallbackTeamIDs", int64(1)).Return([]int64{2, 3}, nil)
+	repo.On("GetTeamMembers", int64(1), "u1").Return([]string{"u2"}, nil)
+	repo.On("GetTeamMembers", int64(2), "u1").Return([]string{"f1", "f2"}, nil)
+
+	pr, err := svc.Create(&domain.PullRequest{ID: "pr-1", Name: "x", AuthorID: "u1"}, "")
+	require.NoError(t, err)
+	assert.Equal(t, "u2", pr.AssignedReviewers[0].UserID)
+	assert.ElementsMatch(t, []string{"f1", "f2"}, pr.ReviewerIDs()[1:])
+	repo.AssertNotCalled(t, "GetTeamMembers", int64(3), mock.Anything)
+}
+
+func TestCreate_OwnTeamEnoughSkipsFallback(t *testing.T) {
+	repo, teams, svc := createFixture(&domain.TeamSettings{ReviewersCount: 2})
+	repo.On("GetTeamMembers", int64(1), "u1").Return([]string{"u2", "u3"}, nil)
+
+	pr, err := svc.Create(&domain.PullRequest{ID: "pr-1", Name: "x", AuthorID: "u1"}, "")
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"u2", "u3"}, pr.ReviewerIDs())
+	for _, r := range pr.AssignedReviewers {
+		assert.False(t, r.Fallback)
+	}
+	teams.AssertNotCalled(t, "GetFallbackTeamIDs", mock.Anything)
+}
+
+func TestCreate_FallbackSkipsAlreadyPicked(t *testing.T) {
+	// участник обеих команд не назначается дважды
+	repo, teams, svc := createFixture(&domain.TeamSettings{ReviewersCount: 2})
+	teams.On("GetFallbackTeamIDs", int64(1)).Return([]int64{2}, nil)
+	repo.On("GetTeamMembers", int64(1), "u1").Return([]string{"u2"}, nil)
+	repo.On("GetTeamMembers", int64(2), "u1").Return([]string{"u2", "f1"}, nil)
+
+	pr, err := svc.Create(&domain.PullRequest{ID: "pr-1", Name: "x", AuthorID: "u1"}, "")
+	require.NoError(t, err)
+	assert.Equal(t, []domain.Reviewer{{UserID: "u2"}, {UserID: "f1", Fallback: true}}, pr.AssignedReviewers)
+}
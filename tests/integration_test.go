@@ -0,0 +1,238 @@
+package tests
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"pr-reviewer/internal/domain"
+	"pr-reviewer/internal/http/handlers"
+	"pr-reviewer/internal/services"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockIdentityRepository struct {
+	mock.Mock
+}
+
+func (m *MockIdentityRepository) Upsert(identity *domain.Identity) error {
+	return m.Called(identity).Error(0)
+}
+
+func (m *MockIdentityRepository) Resolve(provider, login string) (string, error) {
+	args := m.Called(provider, login)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockIdentityRepository) List(userID string) ([]domain.Identity, error) {
+	args := m.Called(userID)
+	return args.Get(0).([]domain.Identity), args.Error(1)
+}
+
+func (m *MockIdentityRepository) Delete(provider, login string) error {
+	return m.Called(provider, login).Error(0)
+}
+
+type MockCodeHostRepository struct {
+	mock.Mock
+}
+
+func (m *MockCodeHostRepository) SaveLink(link *domain.CodeHostPullRequest) error {
+	return m.Called(link).Error(0)
+}
+
+func (m *MockCodeHostRepository) GetLink(prID string) (*domain.CodeHostPullRequest, error) {
+	args := m.Called(prID)
+	link, _ := args.Get(0).(*domain.CodeHostPullRequest)
+	return link, args.Error(1)
+}
+
+func (m *MockCodeHostRepository) Logins(provider string, userIDs []string) ([]string, error) {
+	args := m.Called(provider, userIDs)
+	return args.Get(0).([]string), args.Error(1)
+}
+
+// MockPullRequestService — только методы, которые вызывает IntegrationService
+type MockPullRequestService struct {
+	services.PullRequestService
+	mock.Mock
+}
+
+func (m *MockPullRequestService) Create(pr *domain.PullRequest, actor string) (*domain.PullRequest, error) {
+	args := m.Called(pr, actor)
+	return args.Get(0).(*domain.PullRequest), args.Error(1)
+}
+
+func (m *MockPullRequestService) Merge(prID string, force bool, actor string) (*domain.PullRequest, error) {
+	args := m.Called(prID, force, actor)
+	return args.Get(0).(*domain.PullRequest), args.Error(1)
+}
+
+func readTestdata(t *testing.T, name string) []byte {
+	body, err := os.ReadFile("testdata/" + name)
+	require.NoError(t, err)
+	return body
+}
+
+func TestParseGitHubEvent(t *testing.T) {
+	event, err := services.ParseGitHubEvent("pull_request", readTestdata(t, "github_pull_request_opened.json"))
+	require.NoError(t, err)
+	assert.Equal(t, &domain.CodeHostEvent{
+		Provider:      domain.ProviderGitHub,
+		Action:        domain.CodeHostOpened,
+		PullRequestID: "github-1870234511",
+		Title:         "Add reviewer load statistics",
+		AuthorLogin:   "alice-gh",
+		SenderLogin:   "alice-gh",
+		Repository:    "acme/pr-reviewer",
+		Number:        42,
+	}, event)
+
+	event, err = services.ParseGitHubEvent("pull_request", readTestdata(t, "github_pull_request_closed_merged.json"))
+	require.NoError(t, err)
+	assert.Equal(t, domain.CodeHostMerged, event.Action)
+	assert.Equal(t, "bob-gh", event.SenderLogin)
+
+	// другие события не обрабатываются
+	event, err = services.ParseGitHubEvent("push", []byte(`{}`))
+	assert.NoError(t, err)
+	assert.Nil(t, event)
+
+	_, err = services.ParseGitHubEvent("pull_request", []byte(`{"action":"opened"}`))
+	assert.ErrorIs(t, err, domain.ErrInvalidPayload)
+}
+
+func TestParseGitLabEvent(t *testing.T) {
+	event, err := services.ParseGitLabEvent("Merge Request Hook", readTestdata(t, "gitlab_merge_request_open.json"))
+	require.NoError(t, err)
+	assert.Equal(t, &domain.CodeHostEvent{
+		Provider:      domain.ProviderGitLab,
+		Action:        domain.CodeHostOpened,
+		PullRequestID: "gitlab-99",
+		Title:         "Draft: Add reviewer load statistics",
+		AuthorLogin:   "alice-gl",
+		SenderLogin:   "alice-gl",
+		Draft:         true,
+		Repository:    "acme/pr-reviewer",
+		Number:        7,
+	}, event)
+
+	event, err = services.ParseGitLabEvent("Merge Request Hook", readTestdata(t, "gitlab_merge_request_merge.json"))
+	require.NoError(t, err)
+	assert.Equal(t, domain.CodeHostMerged, event.Action)
+	assert.Equal(t, "gitlab-99", event.PullRequestID)
+
+	event, err = services.ParseGitLabEvent("Merge Request Hook", readTestdata(t, "gitlab_merge_request_ready.json"))
+	require.NoError(t, err)
+	assert.Equal(t, domain.CodeHostReady, event.Action)
+	assert.False(t, event.Draft)
+
+	// update без снятия черновика игнорируется
+	event, err = services.ParseGitLabEvent("Merge Request Hook", []byte(`{"object_kind":"merge_request",
+		"object_attributes":{"id":99,"action":"update","draft":true},
+		"changes":{"title":{"previous":"a","current":"b"}}}`))
+	assert.NoError(t, err)
+	assert.Nil(t, event)
+}
+
+func TestVerifyCodeHostSignatures(t *testing.T) {
+	body := readTestdata(t, "github_pull_request_opened.json")
+
+	assert.True(t, services.VerifyGitHubSignature("s3cret", body, services.SignPayload("s3cret", body)))
+	assert.False(t, services.VerifyGitHubSignature("s3cret", body, services.SignPayload("other", body)))
+	assert.False(t, services.VerifyGitHubSignature("s3cret", body, ""))
+
+	assert.True(t, services.VerifyGitLabToken("token", "token"))
+	assert.False(t, services.VerifyGitLabToken("token", "nope"))
+}
+
+func TestIntegrationService_ApplyOpened(t *testing.T) {
+	identities := new(MockIdentityRepository)
+	identities.On("Resolve", domain.ProviderGitHub, "alice-gh").Return("u1", nil)
+
+	prs := new(MockPullRequestService)
+	created := &domain.PullRequest{ID: "github-1870234511", AuthorID: "u1", Status: domain.StatusOpen}
+	prs.On("Create", mock.MatchedBy(func(pr *domain.PullRequest) bool {
+		return pr.ID == "github-1870234511" && pr.AuthorID == "u1" && pr.Status == ""
+	}), "u1").Return(created, nil)
+
+	links := new(MockCodeHostRepository)
+	links.On("SaveLink", &domain.CodeHostPullRequest{
+		PullRequestID: "github-1870234511",
+		Provider:      domain.ProviderGitHub,
+		Repository:    "acme/pr-reviewer",
+		Number:        42,
+	}).Return(nil)
+
+	svc := services.NewIntegrationService(prs, identities, new(MockUserRepository), links)
+
+	event, err := services.ParseGitHubEvent("pull_request", readTestdata(t, "github_pull_request_opened.json"))
+	require.NoError(t, err)
+
+	got, err := svc.Apply(event)
+	assert.NoError(t, err)
+	assert.Equal(t, created, got)
+	prs.AssertExpectations(t)
+	links.AssertExpectations(t)
+}
+
+func TestIntegrationService_ApplyUnknownAuthor(t *testing.T) {
+	identities := new(MockIdentityRepository)
+	identities.On("Resolve", domain.ProviderGitLab, "alice-gl").Return("", domain.ErrNotFound)
+
+	prs := new(MockPullRequestService)
+	svc := services.NewIntegrationService(prs, identities, new(MockUserRepository), new(MockCodeHostRepository))
+
+	event, err := services.ParseGitLabEvent("Merge Request Hook", readTestdata(t, "gitlab_merge_request_open.json"))
+	require.NoError(t, err)
+
+	_, err = svc.Apply(event)
+	assert.ErrorIs(t, err, domain.ErrUnknownIdentity)
+	prs.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestIntegrationService_ApplyMergedForces(t *testing.T) {
+	identities := new(MockIdentityRepository)
+	identities.On("Resolve", domain.ProviderGitHub, "bob-gh").Return("", domain.ErrNotFound)
+
+	prs := new(MockPullRequestService)
+	merged := &domain.PullRequest{ID: "github-1870234511", Status: domain.StatusMerged}
+	// merge уже случился на GitHub, отправитель не связан с пользователем
+	prs.On("Merge", "github-1870234511", true, "github:bob-gh").Return(merged, nil)
+
+	svc := services.NewIntegrationService(prs, identities, new(MockUserRepository), new(MockCodeHostRepository))
+
+	event, err := services.ParseGitHubEvent("pull_request", readTestdata(t, "github_pull_request_closed_merged.json"))
+	require.NoError(t, err)
+
+	got, err := svc.Apply(event)
+	assert.NoError(t, err)
+	assert.Equal(t, merged, got)
+}
+
+func TestIntegrationHandler_GitLabMergeDraftConflict(t *testing.T) {
+	identities := new(MockIdentityRepository)
+	identities.On("Resolve", domain.ProviderGitLab, "bob-gl").Return("u2", nil)
+
+	prs := new(MockPullRequestService)
+	// MR слит в GitLab, а у нас PR ещё черновик
+	prs.On("Merge", "gitlab-99", true, "u2").Return((*domain.PullRequest)(nil), domain.ErrPRDraft)
+
+	svc := services.NewIntegrationService(prs, identities, new(MockUserRepository), new(MockCodeHostRepository))
+	h := handlers.NewIntegrationHandler(svc, "", "token")
+
+	req := httptest.NewRequest(http.MethodPost, "/integrations/gitlab/webhook",
+		bytes.NewReader(readTestdata(t, "gitlab_merge_request_merge.json")))
+	req.Header.Set("X-Gitlab-Event", "Merge Request Hook")
+	req.Header.Set("X-Gitlab-Token", "token")
+	rec := httptest.NewRecorder()
+	h.GitLabWebhook(rec, req)
+
+	assert.Equal(t, http.StatusConflict, rec.Code)
+	assert.Contains(t, rec.Body.String(), "PR_DRAFT")
+	prs.AssertExpectations(t)
+}
@@ -0,0 +1,126 @@
+package tests
+
+import (
+	"pr-reviewer/internal/domain"
+	"pr-reviewer/internal/services"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func (m *MockUserRepository) IsAvailable(userId string) (bool, error) {
+	args := m.Called(userId)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockPullRequestRepository) RemoveReviewer(prID, userID string) error {
+	return m.Called(prID, userID).Error(0)
+}
+
+// PR команды 1 с ревьюверами r1, r2; кандидаты: m1 — доступен, away — в отпуске, off — неактивен, ext — из другой команды
+func manualFixture(minReviewers int) (*MockPullRequestRepository, services.PullRequestService) {
+	repo := new(MockPullRequestRepository)
+	repo.On("GetByID", "pr-1").Return(&domain.PullRequest{
+		ID: "pr-1", AuthorID: "u1", TeamID: 1, TeamName: "backend", Status: domain.StatusOpen,
+		AssignedReviewers: []domain.Reviewer{{UserID: "r1"}, {UserID: "r2"}},
+	}, nil)
+	repo.On("AssignReviewers", "pr-1", mock.Anything).Return(nil)
+	repo.On("RemoveReviewer", "pr-1", mock.Anything).Return(nil)
+	repo.On("ReplaceReviewer", "pr-1", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	users := new(MockUserRepository)
+	users.On("GetById", "m1").Return(&domain.User{ID: "m1", IsActive: true, TeamID: 1, TeamIDs: []int64{1}}, "backend", nil)
+	users.On("GetById", "away").Return(&domain.User{ID: "away", IsActive: true, TeamID: 1, TeamIDs: []int64{1}}, "backend", nil)
+	users.On("GetById", "off").Return(&domain.User{ID: "off", TeamID: 1, TeamIDs: []int64{1}}, "backend", nil)
+	users.On("GetById", "r1").Return(&domain.User{ID: "r1", IsActive: true, TeamID: 1, TeamIDs: []int64{1}}, "backend", nil)
+	users.On("GetById", "ext").Return(&domain.User{ID: "ext", IsActive: true, TeamID: 2, TeamIDs: []int64{2}}, "search", nil)
+	users.On("IsAvailable", "m1").Return(true, nil)
+	users.On("IsAvailable", "away").Return(false, nil)
+	users.On("IsAvailable", "ext").Return(true, nil)
+
+	teams := new(MockTeamRepository)
+	teams.On("GetSettings", int64(1)).Return(&domain.TeamSettings{ReviewersCount: 2, MinReviewers: minReviewers}, nil)
+
+	sel := services.NewReviewerSelectors(repo, services.StrategyRandom, nil)
+	return repo, services.NewPullRequestService(repo, users, teams, nil, nil, sel, nil)
+}
+
+func TestAddReviewer_OK(t *testing.T) {
+	repo, svc := manualFixture(1)
+
+	pr, err := svc.AddReviewer("pr-1", "ext", "lead")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"r1", "r2", "ext"}, pr.ReviewerIDs())
+	// не из команды PR — отмечается как fallback
+	repo.AssertCalled(t, "AssignReviewers", "pr-1", []domain.Reviewer{{UserID: "ext", Fallback: true}})
+}
+
+func TestAddReviewer_RejectsCandidate(t *testing.T) {
+	cases := map[string]error{
+		"u1":   domain.ErrSelfReview,
+		"off":  domain.ErrUserInactive,
+		"r1":   domain.ErrAlreadyAssigned,
+		"away": domain.ErrUserUnavailable,
+	}
+	for userID, want := range cases {
+		repo, svc := manualFixture(1)
+
+		_, err := svc.AddReviewer("pr-1", userID, "lead")
+		assert.ErrorIs(t, err, want, userID)
+		repo.AssertNotCalled(t, "AssignReviewers", mock.Anything, mock.Anything)
+	}
+}
+
+func TestRemoveReviewer_OK(t *testing.T) {
+	repo, svc := manualFixture(1)
+
+	pr, err := svc.RemoveReviewer("pr-1", "r1", false, "lead")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"r2"}, pr.ReviewerIDs())
+	repo.AssertCalled(t, "RemoveReviewer", "pr-1", "r1")
+}
+
+func TestRemoveReviewer_BelowMinReviewers(t *testing.T) {
+	repo, svc := manualFixture(2)
+
+	_, err := svc.RemoveReviewer("pr-1", "r1", false, "lead")
+	assert.ErrorIs(t, err, domain.ErrNotEnoughReviewers)
+	repo.AssertNotCalled(t, "RemoveReviewer", mock.Anything, mock.Anything)
+}
+
+func TestRemoveReviewer_ForceBelowMinReviewers(t *testing.T) {
+	repo, svc := manualFixture(2)
+
+	pr, err := svc.RemoveReviewer("pr-1", "r1", true, "admin")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"r2"}, pr.ReviewerIDs())
+	repo.AssertCalled(t, "RemoveReviewer", "pr-1", "r1")
+}
+
+func TestRemoveReviewer_NotAssigned(t *testing.T) {
+	_, svc := manualFixture(1)
+
+	_, err := svc.RemoveReviewer("pr-1", "m1", true, "admin")
+	assert.ErrorIs(t, err, domain.ErrNotAssigned)
+}
+
+func TestReassign_ManualNewReviewer(t *testing.T) {
+	repo, svc := manualFixture(1)
+
+	pr, newID, err := svc.Reassign("pr-1", "r1", "m1", "lead")
+	require.NoError(t, err)
+	assert.Equal(t, "m1", newID)
+	assert.Equal(t, []string{"m1", "r2"}, pr.ReviewerIDs())
+	repo.AssertCalled(t, "ReplaceReviewer", "pr-1", "r1", "m1", false)
+	repo.AssertNotCalled(t, "FindReplacement", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestReassign_ManualNewReviewerUnavailable(t *testing.T) {
+	repo, svc := manualFixture(1)
+
+	_, _, err := svc.Reassign("pr-1", "r1", "away", "lead")
+	assert.ErrorIs(t, err, domain.ErrUserUnavailable)
+	repo.AssertNotCalled(t, "ReplaceReviewer", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
@@ -0,0 +1,23 @@
+package tests
+
+import (
+	"errors"
+	"fmt"
+	"pr-reviewer/internal/domain"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergeBlockedError_IsAndMessage(t *testing.T) {
+	var err error = &domain.MergeBlockedError{
+		Required:         2,
+		Approved:         1,
+		Pending:          []string{"u3"},
+		ChangesRequested: []string{"u3"},
+	}
+
+	wrapped := fmt.Errorf("merge: %w", err)
+	assert.True(t, errors.Is(wrapped, domain.ErrMergeBlocked))
+	assert.Equal(t, "approvals 1/2, missing approval from: u3, changes requested by: u3", err.Error())
+}
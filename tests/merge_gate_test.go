@@ -0,0 +1,97 @@
+package tests
+
+import (
+	"errors"
+	"pr-reviewer/internal/domain"
+	"pr-reviewer/internal/services"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func (m *MockPullRequestRepository) Merge(prID string, timestamp string, force bool) error {
+	return m.Called(prID, timestamp, force).Error(0)
+}
+
+func mergeFixture(requiredApprovals int, reviewers ...domain.Reviewer) (*MockPullRequestRepository, services.PullRequestService) {
+	repo := new(MockPullRequestRepository)
+	repo.On("GetByID", "pr-1").Return(&domain.PullRequest{
+		ID: "pr-1", AuthorID: "u1", TeamID: 1, Status: domain.StatusOpen, AssignedReviewers: reviewers,
+	}, nil)
+	repo.On("Merge", "pr-1", mock.Anything, mock.Anything).Return(nil)
+
+	teams := new(MockTeamRepository)
+	teams.On("GetSettings", int64(1)).Return(&domain.TeamSettings{ReviewersCount: 2, RequiredApprovals: requiredApprovals}, nil)
+
+	return repo, services.NewPullRequestService(repo, new(MockUserRepository), teams, nil, nil, nil, nil)
+}
+
+func TestMerge_Approved(t *testing.T) {
+	repo, svc := mergeFixture(1,
+		domain.Reviewer{UserID: "u2", Verdict: domain.VerdictApproved},
+		domain.Reviewer{UserID: "u3"},
+	)
+
+	pr, err := svc.Merge("pr-1", false, "u1")
+	require.NoError(t, err)
+	assert.Equal(t, domain.StatusMerged, pr.Status)
+	assert.False(t, pr.ForceMerged)
+	repo.AssertCalled(t, "Merge", "pr-1", mock.Anything, false)
+}
+
+func TestMerge_BlockedWithoutApprovals(t *testing.T) {
+	repo, svc := mergeFixture(2,
+		domain.Reviewer{UserID: "u2", Verdict: domain.VerdictApproved},
+		// поздний COMMENTED отменил прежний APPROVED
+		domain.Reviewer{UserID: "u3", Verdict: domain.VerdictCommented},
+	)
+
+	_, err := svc.Merge("pr-1", false, "u1")
+	var blocked *domain.MergeBlockedError
+	require.True(t, errors.As(err, &blocked))
+	assert.Equal(t, 2, blocked.Required)
+	assert.Equal(t, 1, blocked.Approved)
+	assert.Equal(t, []string{"u3"}, blocked.Pending)
+	repo.AssertNotCalled(t, "Merge", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestMerge_BlockedByChangesRequested(t *testing.T) {
+	repo, svc := mergeFixture(1,
+		domain.Reviewer{UserID: "u2", Verdict: domain.VerdictApproved},
+		domain.Reviewer{UserID: "u3", Verdict: domain.VerdictChangesRequested},
+	)
+
+	_, err := svc.Merge("pr-1", false, "u1")
+	var blocked *domain.MergeBlockedError
+	require.True(t, errors.As(err, &blocked))
+	assert.Equal(t, []string{"u3"}, blocked.ChangesRequested)
+	repo.AssertNotCalled(t, "Merge", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestMerge_BlockedWithoutReviewers(t *testing.T) {
+	repo, svc := mergeFixture(1)
+
+	_, err := svc.Merge("pr-1", false, "u1")
+	assert.ErrorIs(t, err, domain.ErrMergeBlocked)
+	assert.EqualError(t, err, "approvals 0/1")
+	repo.AssertNotCalled(t, "Merge", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestMerge_BlockedWithTooFewReviewers(t *testing.T) {
+	// одного APPROVED не хватает, даже если других ревьюверов нет
+	_, svc := mergeFixture(2, domain.Reviewer{UserID: "u2", Verdict: domain.VerdictApproved})
+
+	_, err := svc.Merge("pr-1", false, "u1")
+	assert.ErrorIs(t, err, domain.ErrMergeBlocked)
+}
+
+func TestMerge_ForceBypassesGate(t *testing.T) {
+	repo, svc := mergeFixture(1)
+
+	pr, err := svc.Merge("pr-1", true, "admin")
+	require.NoError(t, err)
+	assert.True(t, pr.ForceMerged)
+	repo.AssertCalled(t, "Merge", "pr-1", mock.Anything, true)
+}
@@ -0,0 +1,69 @@
+package tests
+
+import (
+	"errors"
+	"pr-reviewer/internal/domain"
+	"pr-reviewer/internal/services"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockOutboxRepository struct {
+	mock.Mock
+}
+
+func (m *MockOutboxRepository) Pending(limit int) ([]domain.OutboxMessage, error) {
+	args := m.Called(limit)
+	return args.Get(0).([]domain.OutboxMessage), args.Error(1)
+}
+
+func (m *MockOutboxRepository) MarkProcessed(id int64) error {
+	return m.Called(id).Error(0)
+}
+
+func (m *MockOutboxRepository) MarkFailed(msg *domain.OutboxMessage, nextAttemptAt time.Time) error {
+	return m.Called(msg, nextAttemptAt).Error(0)
+}
+
+type MockEventPublisher struct {
+	mock.Mock
+}
+
+func (m *MockEventPublisher) Publish(event domain.Event) error {
+	return m.Called(event).Error(0)
+}
+
+func TestOutboxDispatcher_Drain(t *testing.T) {
+	ok := domain.Event{ID: 10, Type: domain.EventPRCreated}
+	broken := domain.Event{ID: 11, Type: domain.EventPRMerged}
+
+	repo := new(MockOutboxRepository)
+	repo.On("Pending", 10).Return([]domain.OutboxMessage{
+		{ID: 1, Event: ok},
+		{ID: 2, Event: broken, Attempts: 2},
+	}, nil)
+	repo.On("MarkProcessed", int64(1)).Return(nil)
+
+	// неудача: счётчик попыток растёт, повтор — через backoff * 2^(attempts-1)
+	before := time.Now()
+	repo.On("MarkFailed", mock.MatchedBy(func(m *domain.OutboxMessage) bool {
+		return m.ID == 2 && m.Attempts == 3 && m.LastError == "receiver down"
+	}), mock.MatchedBy(func(next time.Time) bool {
+		return !next.Before(before.Add(4*time.Second)) && next.Before(time.Now().Add(5*time.Second))
+	})).Return(nil)
+
+	pub := new(MockEventPublisher)
+	pub.On("Publish", ok).Return(nil)
+	pub.On("Publish", broken).Return(errors.New("receiver down"))
+
+	d := services.NewOutboxDispatcher(repo, pub, time.Second, 10, time.Second)
+
+	n, err := d.Drain()
+	assert.NoError(t, err)
+	assert.Equal(t, 2, n)
+	repo.AssertExpectations(t)
+	repo.AssertNotCalled(t, "MarkProcessed", int64(2))
+}
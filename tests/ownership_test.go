@@ -0,0 +1,102 @@
+package tests
+
+import (
+	"pr-reviewer/internal/domain"
+	"pr-reviewer/internal/repository"
+	"pr-reviewer/internal/services"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func patterns(rules []domain.OwnershipRule) []string {
+	var res []string
+	for _, r := range rules {
+		res = append(res, r.Pattern)
+	}
+	return res
+}
+
+func TestMatchOwnershipRules(t *testing.T) {
+	rules := []domain.OwnershipRule{
+		{ID: 1, Pattern: "*.go", TeamName: "backend"},
+		{ID: 2, Pattern: "/docs/", TeamName: "docs"},
+		{ID: 3, Pattern: "internal/payments/**", UserID: "u7"},
+		{ID: 4, Pattern: "Makefile", UserID: "u1"},
+	}
+
+	cases := []struct {
+		name  string
+		files []string
+		want  []string
+	}{
+		{"extension anywhere", []string{"cmd/main.go"}, []string{"*.go"}},
+		{"anchored directory", []string{"docs/api/index.md"}, []string{"/docs/"}},
+		{"nested docs not anchored", []string{"internal/docs/readme.md"}, nil},
+		{"last match wins", []string{"internal/payments/card.go"}, []string{"internal/payments/**"}},
+		{"basename", []string{"./build/Makefile"}, []string{"Makefile"}},
+		{"several files", []string{"docs/a.md", "x.go"}, []string{"*.go", "/docs/"}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, patterns(services.MatchOwnershipRules(rules, tc.files)))
+		})
+	}
+}
+
+func TestMatchOwnershipRules_DirectoryAnchoring(t *testing.T) {
+	unanchored := []domain.OwnershipRule{{ID: 1, Pattern: "docs/", TeamName: "docs"}}
+	anchored := []domain.OwnershipRule{{ID: 2, Pattern: "/docs/", TeamName: "docs"}}
+
+	// как в gitignore/CODEOWNERS: без "/" в начале или середине каталог ищется на любой глубине
+	assert.Equal(t, []string{"docs/"}, patterns(services.MatchOwnershipRules(unanchored, []string{"docs/index.md"})))
+	assert.Equal(t, []string{"docs/"}, patterns(services.MatchOwnershipRules(unanchored, []string{"a/docs/x"})))
+
+	assert.Equal(t, []string{"/docs/"}, patterns(services.MatchOwnershipRules(anchored, []string{"docs/index.md"})))
+	assert.Nil(t, patterns(services.MatchOwnershipRules(anchored, []string{"a/docs/x"})))
+}
+
+type MockOwnershipRepository struct {
+	repository.OwnershipRepository
+	mock.Mock
+}
+
+func (m *MockOwnershipRepository) List() ([]domain.OwnershipRule, error) {
+	args := m.Called()
+	return args.Get(0).([]domain.OwnershipRule), args.Error(1)
+}
+
+func (m *MockPullRequestRepository) FilterEligible(userIDs []string, exclude string) ([]string, error) {
+	args := m.Called(userIDs, exclude)
+	ids, _ := args.Get(0).([]string)
+	return ids, args.Error(1)
+}
+
+func TestCreate_OwnerPickKeepsRoundRobinQueue(t *testing.T) {
+	users := new(MockUserRepository)
+	users.On("GetById", "u1").Return(&domain.User{ID: "u1", IsActive: true, TeamID: 1, TeamIDs: []int64{1}}, "backend", nil)
+
+	teams := new(MockTeamRepository)
+	teams.On("GetSettings", int64(1)).Return(&domain.TeamSettings{ReviewersCount: 2, MinReviewers: 1, Strategy: services.StrategyRoundRobin}, nil)
+
+	owners := new(MockOwnershipRepository)
+	owners.On("List").Return([]domain.OwnershipRule{{ID: 1, Pattern: "*.go", UserID: "u3"}}, nil)
+
+	repo := new(MockPullRequestRepository)
+	repo.On("Exists", "pr-1").Return(false, nil)
+	repo.On("Create", mock.Anything).Return(nil)
+	repo.On("AssignReviewers", "pr-1", mock.Anything).Return(nil)
+	repo.On("FilterEligible", []string{"u3"}, "u1").Return([]string{"u3"}, nil)
+	repo.On("GetTeamMembers", int64(1), "u1").Return([]string{"u2", "u3", "u4"}, nil)
+
+	sel := services.NewReviewerSelectors(repo, services.StrategyRandom, nil)
+	svc := services.NewPullRequestService(repo, users, teams, owners, nil, sel, nil)
+
+	// владелец u3 не сдвигает очередь команды: round_robin начинает с первого по порядку
+	pr, err := svc.Create(&domain.PullRequest{ID: "pr-1", Name: "x", AuthorID: "u1", ChangedFiles: []string{"main.go"}}, "u1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"u3", "u2"}, pr.ReviewerIDs())
+}
@@ -0,0 +1,147 @@
+package tests
+
+import (
+	"pr-reviewer/internal/domain"
+	"pr-reviewer/internal/repository"
+	"pr-reviewer/internal/services"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockPullRequestRepository — только методы выборок; остальные не вызываются
+type MockPullRequestRepository struct {
+	repository.PullRequestRepository
+	mock.Mock
+
+	events []domain.Event // всё, что сервис записал через AddEvents
+}
+
+func (m *MockPullRequestRepository) GetByReviewer(filter *domain.ReviewFilter, after *domain.PageCursor) ([]domain.PullRequestShort, *domain.PageCursor, error) {
+	args := m.Called(filter, after)
+	next, _ := args.Get(1).(*domain.PageCursor)
+	return args.Get(0).([]domain.PullRequestShort), next, args.Error(2)
+}
+
+func (m *MockPullRequestRepository) List(filter *domain.PullRequestFilter, after *domain.PageCursor) ([]domain.PullRequestSummary, *domain.PageCursor, error) {
+	args := m.Called(filter, after)
+	next, _ := args.Get(1).(*domain.PageCursor)
+	return args.Get(0).([]domain.PullRequestSummary), next, args.Error(2)
+}
+
+func TestCursor_RoundTrip(t *testing.T) {
+	c := &domain.PageCursor{CreatedAt: time.Date(2025, 10, 24, 12, 0, 0, 123456000, time.UTC), ID: "pr-7"}
+
+	encoded := services.EncodeCursor(c, domain.SortCreatedAsc)
+	require.NotNil(t, encoded)
+
+	decoded, err := services.DecodeCursor(*encoded, domain.SortCreatedAsc)
+	require.NoError(t, err)
+	assert.Equal(t, &domain.PageCursor{CreatedAt: c.CreatedAt, ID: "pr-7", Sort: domain.SortCreatedAsc}, decoded)
+
+	_, err = services.DecodeCursor("not-a-cursor", domain.SortCreatedAsc)
+	assert.ErrorIs(t, err, domain.ErrInvalidCursor)
+
+	assert.Nil(t, services.EncodeCursor(nil, domain.SortCreatedAsc))
+}
+
+func TestCursor_RejectsOtherSort(t *testing.T) {
+	c := &domain.PageCursor{CreatedAt: time.Date(2025, 10, 24, 12, 0, 0, 0, time.UTC), ID: "pr-7"}
+
+	_, err := services.DecodeCursor(*services.EncodeCursor(c, domain.SortCreatedDesc), domain.SortCreatedAsc)
+	assert.ErrorIs(t, err, domain.ErrInvalidCursor)
+}
+
+func TestList_CursorFromOtherSort(t *testing.T) {
+	repo := new(MockPullRequestRepository)
+	svc := services.NewPullRequestService(repo, new(MockUserRepository), nil, nil, nil, nil, nil)
+
+	// курсор из выдачи по умолчанию (-created_at) нельзя продолжать по возрастанию
+	c := &domain.PageCursor{CreatedAt: time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC), ID: "pr-2"}
+	_, err := svc.List(&domain.PullRequestFilter{Sort: domain.SortCreatedAsc, Cursor: *services.EncodeCursor(c, domain.SortCreatedDesc)})
+	assert.ErrorIs(t, err, domain.ErrInvalidCursor)
+	repo.AssertNotCalled(t, "List", mock.Anything, mock.Anything)
+}
+
+func TestGetReview_EmptyListAndDefaults(t *testing.T) {
+	users := new(MockUserRepository)
+	users.On("GetById", "u1").Return(&domain.User{ID: "u1", TeamID: 1}, "backend", nil)
+
+	repo := new(MockPullRequestRepository)
+	repo.On("GetByReviewer", mock.MatchedBy(func(f *domain.ReviewFilter) bool {
+		return f.Sort == domain.SortCreatedDesc && f.Limit == domain.DefaultPageLimit
+	}), (*domain.PageCursor)(nil)).Return([]domain.PullRequestShort{}, nil, nil)
+
+	svc := services.NewPullRequestService(repo, users, nil, nil, nil, nil, nil)
+
+	page, err := svc.GetReview(&domain.ReviewFilter{UserID: "u1"})
+	require.NoError(t, err)
+	assert.Empty(t, page.PullRequests)
+	assert.Nil(t, page.NextCursor)
+}
+
+func TestGetReview_PassesCursor(t *testing.T) {
+	users := new(MockUserRepository)
+	users.On("GetById", "u1").Return(&domain.User{ID: "u1", TeamID: 1}, "backend", nil)
+
+	first := &domain.PageCursor{CreatedAt: time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC), ID: "pr-2"}
+	second := &domain.PageCursor{CreatedAt: time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC), ID: "pr-1"}
+
+	repo := new(MockPullRequestRepository)
+	repo.On("GetByReviewer", mock.Anything, &domain.PageCursor{CreatedAt: first.CreatedAt, ID: first.ID, Sort: domain.SortCreatedDesc}).
+		Return([]domain.PullRequestShort{{ID: "pr-1"}}, second, nil)
+
+	svc := services.NewPullRequestService(repo, users, nil, nil, nil, nil, nil)
+
+	page, err := svc.GetReview(&domain.ReviewFilter{UserID: "u1", Limit: 1, Cursor: *services.EncodeCursor(first, domain.SortCreatedDesc)})
+	require.NoError(t, err)
+	assert.Equal(t, services.EncodeCursor(second, domain.SortCreatedDesc), page.NextCursor)
+}
+
+func TestGetReview_InvalidFilter(t *testing.T) {
+	svc := services.NewPullRequestService(new(MockPullRequestRepository), new(MockUserRepository), nil, nil, nil, nil, nil)
+
+	after := time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC)
+	before := after.Add(-time.Hour)
+
+	for _, f := range []*domain.ReviewFilter{
+		{UserID: "u1", Statuses: []string{"WIP"}},
+		{UserID: "u1", Sort: "title"},
+		{UserID: "u1", Limit: domain.MaxPageLimit + 1},
+		{UserID: "u1", CreatedAfter: &after, CreatedBefore: &before},
+	} {
+		_, err := svc.GetReview(f)
+		assert.ErrorIs(t, err, domain.ErrInvalidFilter)
+	}
+}
+
+func TestList_ReturnsReviewersAndCursor(t *testing.T) {
+	next := &domain.PageCursor{CreatedAt: time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC), ID: "pr-2"}
+	summary := domain.PullRequestSummary{
+		PullRequestShort:  domain.PullRequestShort{ID: "pr-2", Status: domain.StatusOpen},
+		AssignedReviewers: []domain.Reviewer{{UserID: "u2"}, {UserID: "u3"}},
+	}
+
+	repo := new(MockPullRequestRepository)
+	repo.On("List", mock.MatchedBy(func(f *domain.PullRequestFilter) bool {
+		return f.TeamName == "backend" && f.Limit == 1 && f.Sort == domain.SortCreatedDesc
+	}), (*domain.PageCursor)(nil)).Return([]domain.PullRequestSummary{summary}, next, nil)
+
+	svc := services.NewPullRequestService(repo, new(MockUserRepository), nil, nil, nil, nil, nil)
+
+	page, err := svc.List(&domain.PullRequestFilter{TeamName: "backend", Statuses: []string{domain.StatusOpen}, Limit: 1})
+	require.NoError(t, err)
+	assert.Equal(t, []domain.PullRequestSummary{summary}, page.PullRequests)
+	assert.Equal(t, services.EncodeCursor(next, domain.SortCreatedDesc), page.NextCursor)
+}
+
+func TestList_InvalidMergedRange(t *testing.T) {
+	svc := services.NewPullRequestService(new(MockPullRequestRepository), new(MockUserRepository), nil, nil, nil, nil, nil)
+
+	day := time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC)
+	_, err := svc.List(&domain.PullRequestFilter{MergedAfter: &day, MergedBefore: &day})
+	assert.ErrorIs(t, err, domain.ErrInvalidFilter)
+}
@@ -0,0 +1,64 @@
+package tests
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"pr-reviewer/internal/domain"
+	"pr-reviewer/internal/http/handlers"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func (m *MockPullRequestService) Get(prID string) (*domain.PullRequest, error) {
+	args := m.Called(prID)
+	pr, _ := args.Get(0).(*domain.PullRequest)
+	return pr, args.Error(1)
+}
+
+func getPR(h *handlers.PullRequestHandler, ifNoneMatch string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/pullRequest/get?pull_request_id=pr-1", nil)
+	if ifNoneMatch != "" {
+		req.Header.Set("If-None-Match", ifNoneMatch)
+	}
+	rec := httptest.NewRecorder()
+	h.Get(rec, req)
+	return rec
+}
+
+func TestPullRequestHandler_Get_ETag(t *testing.T) {
+	pr := &domain.PullRequest{
+		ID: "pr-1", Name: "Add search", AuthorID: "u1", Status: domain.StatusOpen,
+		CreatedAt:         "2025-10-24T12:00:00Z",
+		AssignedReviewers: []domain.Reviewer{{UserID: "u2"}},
+	}
+
+	svc := new(MockPullRequestService)
+	svc.On("Get", "pr-1").Return(pr, nil)
+	h := handlers.NewPullRequestHandler(svc, "")
+
+	first := getPR(h, "")
+	require.Equal(t, http.StatusOK, first.Code)
+	etag := first.Header().Get("ETag")
+	require.NotEmpty(t, etag)
+	assert.Contains(t, first.Body.String(), `"pull_request_id":"pr-1"`)
+
+	notModified := getPR(h, etag)
+	assert.Equal(t, http.StatusNotModified, notModified.Code)
+	assert.Empty(t, notModified.Body.String())
+
+	// новый вердикт меняет ETag
+	pr.AssignedReviewers[0].Verdict = domain.VerdictApproved
+	changed := getPR(h, etag)
+	assert.Equal(t, http.StatusOK, changed.Code)
+	assert.NotEqual(t, etag, changed.Header().Get("ETag"))
+}
+
+func TestPullRequestHandler_Get_NotFound(t *testing.T) {
+	svc := new(MockPullRequestService)
+	svc.On("Get", "pr-1").Return(nil, domain.ErrNotFound)
+
+	rec := getPR(handlers.NewPullRequestHandler(svc, ""), "")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
@@ -0,0 +1,137 @@
+package tests
+
+import (
+	"pr-reviewer/internal/domain"
+	"pr-reviewer/internal/services"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func (m *MockPullRequestRepository) SetStatus(prID, from, to string, closedAt *string) error {
+	return m.Called(prID, from, to, closedAt).Error(0)
+}
+
+func lifecycleFixture(pr *domain.PullRequest) (*MockPullRequestRepository, *MockTeamRepository, services.PullRequestService) {
+	repo := new(MockPullRequestRepository)
+	repo.On("GetByID", "pr-1").Return(pr, nil)
+
+	teams := new(MockTeamRepository)
+	sel := services.NewReviewerSelectors(repo, services.StrategyRandom, nil)
+
+	return repo, teams, services.NewPullRequestService(repo, new(MockUserRepository), teams, nil, nil, sel, nil)
+}
+
+func TestClose_FromOpenAndDraft(t *testing.T) {
+	for _, status := range []string{domain.StatusOpen, domain.StatusDraft} {
+		repo, _, svc := lifecycleFixture(&domain.PullRequest{ID: "pr-1", AuthorID: "u1", TeamID: 1, Status: status})
+		repo.On("SetStatus", "pr-1", status, domain.StatusClosed, mock.Anything).Return(nil)
+
+		pr, err := svc.Close("pr-1", "u1")
+		require.NoError(t, err, status)
+		assert.Equal(t, domain.StatusClosed, pr.Status)
+		assert.NotNil(t, pr.ClosedAt)
+		assert.Equal(t, status, pr.ClosedFrom)
+	}
+}
+
+func TestClose_Idempotent(t *testing.T) {
+	repo, _, svc := lifecycleFixture(&domain.PullRequest{ID: "pr-1", Status: domain.StatusClosed})
+
+	pr, err := svc.Close("pr-1", "")
+	require.NoError(t, err)
+	assert.Equal(t, domain.StatusClosed, pr.Status)
+	repo.AssertNotCalled(t, "SetStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestClose_Merged(t *testing.T) {
+	_, _, svc := lifecycleFixture(&domain.PullRequest{ID: "pr-1", Status: domain.StatusMerged})
+
+	_, err := svc.Close("pr-1", "")
+	assert.ErrorIs(t, err, domain.ErrPRMerged)
+}
+
+func TestClose_ConcurrentUpdate(t *testing.T) {
+	// между чтением и записью PR успели смержить
+	repo, _, svc := lifecycleFixture(&domain.PullRequest{ID: "pr-1", Status: domain.StatusOpen})
+	repo.On("SetStatus", "pr-1", domain.StatusOpen, domain.StatusClosed, mock.Anything).Return(domain.ErrConcurrentUpdate)
+
+	_, err := svc.Close("pr-1", "")
+	assert.ErrorIs(t, err, domain.ErrConcurrentUpdate)
+}
+
+func TestReopen_ClosedDraftStaysDraft(t *testing.T) {
+	repo, teams, svc := lifecycleFixture(&domain.PullRequest{
+		ID: "pr-1", AuthorID: "u1", TeamID: 1, Status: domain.StatusClosed, ClosedFrom: domain.StatusDraft,
+		AssignedReviewers: []domain.Reviewer{},
+	})
+	repo.On("SetStatus", "pr-1", domain.StatusClosed, domain.StatusDraft, (*string)(nil)).Return(nil)
+
+	pr, err := svc.Reopen("pr-1", "u1")
+	require.NoError(t, err)
+	assert.Equal(t, domain.StatusDraft, pr.Status)
+	assert.Nil(t, pr.ClosedAt)
+	assert.Empty(t, pr.AssignedReviewers)
+	teams.AssertNotCalled(t, "GetSettings", mock.Anything)
+	repo.AssertNotCalled(t, "AssignReviewers", mock.Anything, mock.Anything)
+}
+
+func TestReopen_ClosedOpenKeepsReviewers(t *testing.T) {
+	repo, teams, svc := lifecycleFixture(&domain.PullRequest{
+		ID: "pr-1", AuthorID: "u1", TeamID: 1, Status: domain.StatusClosed, ClosedFrom: domain.StatusOpen,
+		AssignedReviewers: []domain.Reviewer{{UserID: "u2"}},
+	})
+	repo.On("SetStatus", "pr-1", domain.StatusClosed, domain.StatusOpen, (*string)(nil)).Return(nil)
+
+	pr, err := svc.Reopen("pr-1", "u1")
+	require.NoError(t, err)
+	assert.Equal(t, domain.StatusOpen, pr.Status)
+	assert.Equal(t, []string{"u2"}, pr.ReviewerIDs())
+	teams.AssertNotCalled(t, "GetSettings", mock.Anything)
+}
+
+func TestReopen_Merged(t *testing.T) {
+	_, _, svc := lifecycleFixture(&domain.PullRequest{ID: "pr-1", Status: domain.StatusMerged})
+
+	_, err := svc.Reopen("pr-1", "")
+	assert.ErrorIs(t, err, domain.ErrPRMerged)
+}
+
+func TestReady_AssignsReviewers(t *testing.T) {
+	repo, teams, svc := lifecycleFixture(&domain.PullRequest{
+		ID: "pr-1", AuthorID: "u1", TeamID: 1, Status: domain.StatusDraft, AssignedReviewers: []domain.Reviewer{},
+	})
+	teams.On("GetSettings", int64(1)).Return(&domain.TeamSettings{ReviewersCount: 1}, nil)
+	repo.On("GetTeamMembers", int64(1), "u1").Return([]string{"u2"}, nil)
+	repo.On("AssignReviewers", "pr-1", []domain.Reviewer{{UserID: "u2"}}).Return(nil)
+	repo.On("SetStatus", "pr-1", domain.StatusDraft, domain.StatusOpen, (*string)(nil)).Return(nil)
+
+	pr, err := svc.Ready("pr-1", "u1")
+	require.NoError(t, err)
+	assert.Equal(t, domain.StatusOpen, pr.Status)
+	assert.Equal(t, []string{"u2"}, pr.ReviewerIDs())
+}
+
+func TestReady_ClosedAndIdempotent(t *testing.T) {
+	_, _, svc := lifecycleFixture(&domain.PullRequest{ID: "pr-1", Status: domain.StatusClosed})
+	_, err := svc.Ready("pr-1", "")
+	assert.ErrorIs(t, err, domain.ErrPRClosed)
+
+	repo, _, svc := lifecycleFixture(&domain.PullRequest{ID: "pr-1", Status: domain.StatusOpen})
+	pr, err := svc.Ready("pr-1", "")
+	require.NoError(t, err)
+	assert.Equal(t, domain.StatusOpen, pr.Status)
+	repo.AssertNotCalled(t, "SetStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestMerge_DraftOrClosed(t *testing.T) {
+	for status, want := range map[string]error{domain.StatusDraft: domain.ErrPRDraft, domain.StatusClosed: domain.ErrPRClosed} {
+		repo, _, svc := lifecycleFixture(&domain.PullRequest{ID: "pr-1", Status: status})
+
+		_, err := svc.Merge("pr-1", true, "")
+		assert.ErrorIs(t, err, want, status)
+		repo.AssertNotCalled(t, "Merge", mock.Anything, mock.Anything, mock.Anything)
+	}
+}
@@ -0,0 +1,91 @@
+package tests
+
+import (
+	"pr-reviewer/internal/domain"
+	"pr-reviewer/internal/repository"
+	"pr-reviewer/internal/services"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func (m *MockPullRequestRepository) WithTx(fn func(tx repository.PullRequestRepository) error) error {
+	return fn(m)
+}
+
+func (m *MockPullRequestRepository) Exists(prID string) (bool, error) {
+	args := m.Called(prID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockPullRequestRepository) Create(pr *domain.PullRequest) error {
+	return m.Called(pr).Error(0)
+}
+
+func (m *MockPullRequestRepository) AssignReviewers(prID string, reviewers []domain.Reviewer) error {
+	return m.Called(prID, reviewers).Error(0)
+}
+
+func (m *MockPullRequestRepository) AddEvents(events ...domain.Event) error {
+	m.events = append(m.events, events...)
+	return nil
+}
+
+func (m *MockPullRequestRepository) GetTeamMembers(teamID int64, exclude string) ([]string, error) {
+	args := m.Called(teamID, exclude)
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockTeamRepository) GetSettings(teamID int64) (*domain.TeamSettings, error) {
+	args := m.Called(teamID)
+	return args.Get(0).(*domain.TeamSettings), args.Error(1)
+}
+
+func (m *MockTeamRepository) GetFallbackTeamIDs(teamID int64) ([]int64, error) {
+	args := m.Called(teamID)
+	ids, _ := args.Get(0).([]int64)
+	return ids, args.Error(1)
+}
+
+func TestCreate_TeamNameSelectsTargetTeam(t *testing.T) {
+	users := new(MockUserRepository)
+	users.On("GetById", "u1").Return(&domain.User{ID: "u1", IsActive: true, TeamID: 1, TeamIDs: []int64{1, 2}}, "backend", nil)
+
+	teams := new(MockTeamRepository)
+	teams.On("Get", "platform").Return(&domain.Team{ID: 2, TeamName: "platform"}, nil)
+	teams.On("GetSettings", int64(2)).Return(&domain.TeamSettings{ReviewersCount: 1, MinReviewers: 1}, nil)
+
+	// кандидаты только из platform, а не из основной команды автора
+	repo := new(MockPullRequestRepository)
+	repo.On("Exists", "pr-1").Return(false, nil)
+	repo.On("GetTeamMembers", int64(2), "u1").Return([]string{"p1"}, nil)
+	repo.On("Create", mock.MatchedBy(func(pr *domain.PullRequest) bool { return pr.TeamID == 2 })).Return(nil)
+	repo.On("AssignReviewers", "pr-1", []domain.Reviewer{{UserID: "p1"}}).Return(nil)
+
+	sel := services.NewReviewerSelectors(repo, services.StrategyRandom, nil)
+	svc := services.NewPullRequestService(repo, users, teams, nil, nil, sel, nil)
+
+	pr, err := svc.Create(&domain.PullRequest{ID: "pr-1", Name: "infra", AuthorID: "u1", TeamName: "platform"}, "u1")
+	require.NoError(t, err)
+	assert.Equal(t, "platform", pr.TeamName)
+	assert.Equal(t, []string{"p1"}, pr.ReviewerIDs())
+	repo.AssertNotCalled(t, "GetTeamMembers", int64(1), mock.Anything)
+}
+
+func TestCreate_UnknownTeamName(t *testing.T) {
+	users := new(MockUserRepository)
+	users.On("GetById", "u1").Return(&domain.User{ID: "u1", TeamID: 1, TeamIDs: []int64{1}}, "backend", nil)
+
+	teams := new(MockTeamRepository)
+	teams.On("Get", "ghost").Return(nil, domain.ErrNotFound)
+
+	repo := new(MockPullRequestRepository)
+	repo.On("Exists", "pr-1").Return(false, nil)
+
+	svc := services.NewPullRequestService(repo, users, teams, nil, nil, nil, nil)
+
+	_, err := svc.Create(&domain.PullRequest{ID: "pr-1", Name: "x", AuthorID: "u1", TeamName: "ghost"}, "")
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+}
@@ -0,0 +1,96 @@
+package tests
+
+import (
+	"errors"
+	"pr-reviewer/internal/domain"
+	"pr-reviewer/internal/services"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func (m *MockPullRequestRepository) GetOpenIDsByReviewer(userID string, teamID int64) ([]string, error) {
+	args := m.Called(userID, teamID)
+	ids, _ := args.Get(0).([]string)
+	return ids, args.Error(1)
+}
+
+func TestReassignAll_ReportsReasonAndPending(t *testing.T) {
+	repo := new(MockPullRequestRepository)
+	teams := new(MockTeamRepository)
+
+	// у каждого PR своя команда: pr-1 — замена есть, pr-2 — все заняты, pr-3 — сбой, pr-4 не обработан
+	repo.On("GetOpenIDsByReviewer", "u2", int64(0)).Return([]string{"pr-1", "pr-2", "pr-3", "pr-4"}, nil)
+	for i, prID := range []string{"pr-1", "pr-2", "pr-3", "pr-4"} {
+		teamID := int64(i + 1)
+		repo.On("GetByID", prID).Return(&domain.PullRequest{
+			ID: prID, AuthorID: "u1", TeamID: teamID, Status: domain.StatusOpen,
+			AssignedReviewers: []domain.Reviewer{{UserID: "u2"}},
+		}, nil)
+		teams.On("GetFallbackTeamIDs", teamID).Return(nil, nil)
+	}
+	teams.On("GetSettings", int64(1)).Return(domain.DefaultTeamSettings(), nil)
+	teams.On("GetSettings", int64(2)).Return(domain.DefaultTeamSettings(), nil)
+	teams.On("GetSettings", int64(3)).Return((*domain.TeamSettings)(nil), errors.New("connection reset"))
+
+	repo.On("FindReplacement", int64(1), "u1", "u2", []string{"u2"}).Return([]string{"u5"}, nil)
+	repo.On("ReplaceReviewer", "pr-1", "u2", "u5", false).Return(nil)
+	repo.On("FindReplacement", int64(2), "u1", "u2", []string{"u2"}).Return(nil, domain.ErrNoCandidate)
+	repo.On("CountAtCapacity", []int64{2}, []string{"u2", "u1"}).Return(1, nil)
+
+	sel := services.NewReviewerSelectors(repo, services.StrategyRandom, nil)
+	svc := services.NewPullRequestService(repo, new(MockUserRepository), teams, nil, nil, sel, nil)
+
+	report, err := svc.ReassignAll("u2", "lead")
+	require.Error(t, err)
+	require.NotNil(t, report)
+	assert.Equal(t, []domain.ReassignedReview{{PullRequestID: "pr-1", ReplacedBy: "u5"}}, report.Reassigned)
+	assert.Equal(t, []domain.SkippedReview{{PullRequestID: "pr-2", Reason: "NO_CAPACITY"}}, report.NoCandidate)
+	assert.Equal(t, []string{"pr-3", "pr-4"}, report.Pending)
+	repo.AssertNotCalled(t, "GetByID", "pr-4")
+}
+
+func TestUserService_SetIsActive_PartialReassignment(t *testing.T) {
+	users := new(MockUserRepository)
+	users.On("GetById", "u1").Return(&domain.User{ID: "u1", UserName: "Alice", IsActive: true, TeamID: 1}, "backend", nil)
+	users.On("SetIsActive", "u1", false, mock.Anything).Return(nil)
+
+	report := &domain.ReassignReport{
+		Reassigned: []domain.ReassignedReview{{PullRequestID: "pr-1", ReplacedBy: "u2"}},
+		Pending:    []string{"pr-2"},
+	}
+	reassigner := new(MockReviewReassigner)
+	reassigner.On("ReassignAll", "u1", "lead").Return(report, errors.New("connection reset"))
+
+	svc := services.NewUserService(users, reassigner)
+
+	// пользователь уже деактивирован: отдаём его и частичный отчёт вместе с ошибкой
+	user, got, err := svc.SetIsActive("u1", false, "lead")
+	assert.Error(t, err)
+	require.NotNil(t, user)
+	assert.False(t, user.IsActive)
+	assert.Equal(t, report, got)
+}
+
+func TestUserService_SetIsActive_RepeatDeactivationFinishesReassignment(t *testing.T) {
+	users := new(MockUserRepository)
+	users.On("GetById", "u1").Return(&domain.User{ID: "u1", UserName: "Alice", IsActive: false, TeamID: 1}, "backend", nil)
+
+	report := &domain.ReassignReport{
+		Reassigned:  []domain.ReassignedReview{{PullRequestID: "pr-2", ReplacedBy: "u3"}},
+		NoCandidate: []domain.SkippedReview{},
+	}
+	reassigner := new(MockReviewReassigner)
+	reassigner.On("ReassignAll", "u1", "lead").Return(report, nil)
+
+	svc := services.NewUserService(users, reassigner)
+
+	// прошлый вызов оставил pending: флаг уже снят, повтор дочищает ревью
+	user, got, err := svc.SetIsActive("u1", false, "lead")
+	require.NoError(t, err)
+	assert.False(t, user.IsActive)
+	assert.Equal(t, report, got)
+	users.AssertNotCalled(t, "SetIsActive", mock.Anything, mock.Anything, mock.Anything)
+}
@@ -0,0 +1,71 @@
+package tests
+
+import (
+	"pr-reviewer/internal/domain"
+	"pr-reviewer/internal/services"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func (m *MockPullRequestRepository) AddReview(review *domain.Review) error {
+	args := m.Called(review)
+	review.ID = 1
+	review.CreatedAt = "2025-07-01T10:00:00Z"
+	return args.Error(0)
+}
+
+func reviewFixture(status string) (*MockPullRequestRepository, services.PullRequestService) {
+	repo := new(MockPullRequestRepository)
+	repo.On("GetByID", "pr-1").Return(&domain.PullRequest{
+		ID: "pr-1", AuthorID: "u1", TeamID: 1, Status: status,
+		AssignedReviewers: []domain.Reviewer{{UserID: "u2"}, {UserID: "u3"}},
+	}, nil)
+	repo.On("AddReview", mock.Anything).Return(nil)
+
+	return repo, services.NewPullRequestService(repo, new(MockUserRepository), new(MockTeamRepository), nil, nil, nil, nil)
+}
+
+func TestReview_InvalidVerdict(t *testing.T) {
+	repo, svc := reviewFixture(domain.StatusOpen)
+
+	_, err := svc.Review(&domain.Review{PullRequestID: "pr-1", ReviewerID: "u2", Verdict: "LGTM"})
+	assert.ErrorIs(t, err, domain.ErrInvalidVerdict)
+	repo.AssertNotCalled(t, "GetByID", mock.Anything)
+}
+
+func TestReview_OnlyAssignedReviewer(t *testing.T) {
+	repo, svc := reviewFixture(domain.StatusOpen)
+
+	for _, userID := range []string{"u1", "u9"} {
+		_, err := svc.Review(&domain.Review{PullRequestID: "pr-1", ReviewerID: userID, Verdict: domain.VerdictApproved})
+		assert.ErrorIs(t, err, domain.ErrNotAssigned, userID)
+	}
+	repo.AssertNotCalled(t, "AddReview", mock.Anything)
+}
+
+func TestReview_ClosedOrMergedPR(t *testing.T) {
+	for status, want := range map[string]error{domain.StatusMerged: domain.ErrPRMerged, domain.StatusClosed: domain.ErrPRClosed} {
+		repo, svc := reviewFixture(status)
+
+		_, err := svc.Review(&domain.Review{PullRequestID: "pr-1", ReviewerID: "u2", Verdict: domain.VerdictApproved})
+		assert.ErrorIs(t, err, want, status)
+		repo.AssertNotCalled(t, "AddReview", mock.Anything)
+	}
+}
+
+func TestReview_LatestVerdictWins(t *testing.T) {
+	_, svc := reviewFixture(domain.StatusOpen)
+
+	_, err := svc.Review(&domain.Review{PullRequestID: "pr-1", ReviewerID: "u2", Verdict: domain.VerdictApproved})
+	require.NoError(t, err)
+
+	// поздний COMMENTED отменяет прежний APPROVED
+	pr, err := svc.Review(&domain.Review{PullRequestID: "pr-1", ReviewerID: "u2", Verdict: domain.VerdictCommented})
+	require.NoError(t, err)
+	assert.Equal(t, domain.VerdictCommented, pr.AssignedReviewers[0].Verdict)
+	require.NotNil(t, pr.AssignedReviewers[0].VerdictAt)
+	assert.Empty(t, pr.AssignedReviewers[1].Verdict)
+}
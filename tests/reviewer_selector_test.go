@@ -0,0 +1,48 @@
+package tests
+
+import (
+	"pr-reviewer/internal/services"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRoundRobinSelector_Rotates(t *testing.T) {
+	sel := services.NewRoundRobinSelector()
+	candidates := []string{"u3", "u1", "u2"}
+
+	first, err := sel.Select(1, candidates, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"u1", "u2"}, first)
+
+	second, err := sel.Select(1, candidates, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"u3", "u1"}, second)
+
+	// у другой команды своя очередь
+	other, err := sel.Select(2, candidates, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"u1"}, other)
+}
+
+func TestRandomSelector_LimitsCount(t *testing.T) {
+	sel := services.NewRandomSelector()
+
+	selected, err := sel.Select(1, []string{"u1", "u2", "u3"}, 2)
+	assert.NoError(t, err)
+	assert.Len(t, selected, 2)
+	assert.NotEqual(t, selected[0], selected[1])
+
+	selected, err = sel.Select(1, []string{"u1"}, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"u1"}, selected)
+}
+
+func TestRankByLoad_TiesByUserID(t *testing.T) {
+	load := map[string]int{"u1": 3, "u2": 0, "u4": 1}
+
+	ranked := services.RankByLoad([]string{"u1", "u4", "u3", "u2"}, load)
+
+	// u2 и u3 без открытых ревью — порядок по user_id
+	assert.Equal(t, []string{"u2", "u3", "u4", "u1"}, ranked)
+}
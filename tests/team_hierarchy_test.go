@@ -0,0 +1,125 @@
+package tests
+
+import (
+	"pr-reviewer/internal/domain"
+	"pr-reviewer/internal/services"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func (m *MockTeamRepository) Subteams(teamID int64) ([]domain.Team, error) {
+	args := m.Called(teamID)
+	teams, _ := args.Get(0).([]domain.Team)
+	return teams, args.Error(1)
+}
+
+func (m *MockTeamRepository) SetParent(teamID, parentID int64) error {
+	return m.Called(teamID, parentID).Error(0)
+}
+
+func (m *MockTeamRepository) GetSiblingIDs(teamID int64) ([]int64, error) {
+	args := m.Called(teamID)
+	ids, _ := args.Get(0).([]int64)
+	return ids, args.Error(1)
+}
+
+func (m *MockTeamRepository) GetAncestorIDs(teamID int64) ([]int64, error) {
+	args := m.Called(teamID)
+	ids, _ := args.Get(0).([]int64)
+	return ids, args.Error(1)
+}
+
+func (m *MockPullRequestRepository) GetByID(prID string) (*domain.PullRequest, error) {
+	args := m.Called(prID)
+	pr, _ := args.Get(0).(*domain.PullRequest)
+	return pr, args.Error(1)
+}
+
+func (m *MockPullRequestRepository) FindReplacement(teamID int64, authorID, oldReviewerID string, assigned []string) ([]string, error) {
+	args := m.Called(teamID, authorID, oldReviewerID, assigned)
+	ids, _ := args.Get(0).([]string)
+	return ids, args.Error(1)
+}
+
+func (m *MockPullRequestRepository) ReplaceReviewer(prID, oldID, newID string, fallback bool) error {
+	return m.Called(prID, oldID, newID, fallback).Error(0)
+}
+
+func (m *MockPullRequestRepository) CountAtCapacity(teamIDs []int64, exclude []string) (int, error) {
+	args := m.Called(teamIDs, exclude)
+	return args.Int(0), args.Error(1)
+}
+
+func reassignFixture(escalation string) (*MockPullRequestRepository, *MockTeamRepository, services.PullRequestService) {
+	repo := new(MockPullRequestRepository)
+	repo.On("GetByID", "pr-1").Return(&domain.PullRequest{
+		ID: "pr-1", AuthorID: "u1", TeamID: 2, TeamName: "search", Status: domain.StatusOpen,
+		AssignedReviewers: []domain.Reviewer{{UserID: "r1"}},
+	}, nil)
+	repo.On("FindReplacement", int64(2), "u1", "r1", []string{"r1"}).Return(nil, domain.ErrNoCandidate)
+
+	teams := new(MockTeamRepository)
+	teams.On("GetSettings", int64(2)).Return(&domain.TeamSettings{ReviewersCount: 2, Escalation: escalation}, nil)
+	teams.On("GetFallbackTeamIDs", int64(2)).Return(nil, nil)
+
+	sel := services.NewReviewerSelectors(repo, services.StrategyRandom, nil)
+	return repo, teams, services.NewPullRequestService(repo, new(MockUserRepository), teams, nil, nil, sel, nil)
+}
+
+func TestReassign_EscalatesToParentTeam(t *testing.T) {
+	repo, teams, svc := reassignFixture(services.EscalationParent)
+	teams.On("GetAncestorIDs", int64(2)).Return([]int64{1}, nil)
+	repo.On("FindReplacement", int64(1), "u1", "r1", []string{"r1"}).Return([]string{"lead"}, nil)
+	repo.On("ReplaceReviewer", "pr-1", "r1", "lead", true).Return(nil)
+
+	pr, newID, err := svc.Reassign("pr-1", "r1", "", "")
+	require.NoError(t, err)
+	assert.Equal(t, "lead", newID)
+	assert.True(t, pr.AssignedReviewers[0].Fallback)
+	teams.AssertNotCalled(t, "GetSiblingIDs", mock.Anything)
+}
+
+func TestReassign_NoEscalationFails(t *testing.T) {
+	repo, teams, svc := reassignFixture("")
+	repo.On("CountAtCapacity", []int64{2}, []string{"r1", "u1"}).Return(0, nil)
+
+	_, _, err := svc.Reassign("pr-1", "r1", "", "")
+	assert.ErrorIs(t, err, domain.ErrNoCandidate)
+	teams.AssertNotCalled(t, "GetAncestorIDs", mock.Anything)
+}
+
+func TestTeamService_SetParent_RejectsSubteam(t *testing.T) {
+	teams := new(MockTeamRepository)
+	teams.On("Get", "platform").Return(&domain.Team{ID: 1, TeamName: "platform"}, nil)
+	teams.On("Get", "infra").Return(&domain.Team{ID: 3, TeamName: "infra", ParentID: 1}, nil)
+	teams.On("Subteams", int64(1)).Return([]domain.Team{{ID: 3, TeamName: "infra", ParentID: 1}}, nil)
+
+	svc := services.NewTeamService(teams, new(MockUserRepository), new(MockReviewReassigner))
+
+	_, err := svc.SetParent("platform", "infra")
+	assert.ErrorIs(t, err, domain.ErrInvalidParent)
+	teams.AssertNotCalled(t, "SetParent", mock.Anything, mock.Anything)
+}
+
+func TestTeamService_GetTeam_NestsSubteams(t *testing.T) {
+	teams := new(MockTeamRepository)
+	teams.On("Get", "engineering").Return(&domain.Team{ID: 1, TeamName: "engineering"}, nil)
+	teams.On("Subteams", int64(1)).Return([]domain.Team{
+		{ID: 2, TeamName: "platform", ParentID: 1},
+		{ID: 3, TeamName: "product", ParentID: 1},
+		{ID: 4, TeamName: "infra", ParentID: 2},
+	}, nil)
+
+	svc := services.NewTeamService(teams, new(MockUserRepository), new(MockReviewReassigner))
+
+	team, err := svc.GetTeam("engineering", true)
+	require.NoError(t, err)
+	require.Len(t, team.Subteams, 2)
+	assert.Equal(t, "platform", team.Subteams[0].TeamName)
+	require.Len(t, team.Subteams[0].Subteams, 1)
+	assert.Equal(t, "infra", team.Subteams[0].Subteams[0].TeamName)
+	assert.Empty(t, team.Subteams[1].Subteams)
+}
@@ -0,0 +1,110 @@
+package tests
+
+import (
+	"pr-reviewer/internal/domain"
+	"pr-reviewer/internal/services"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func (m *MockTeamRepository) GetLeadIDs(teamID int64) ([]string, error) {
+	args := m.Called(teamID)
+	ids, _ := args.Get(0).([]string)
+	return ids, args.Error(1)
+}
+
+func leadCreateFixture(settings *domain.TeamSettings) (*MockPullRequestRepository, *MockTeamRepository, services.PullRequestService) {
+	users := new(MockUserRepository)
+	users.On("GetById", "u1").Return(&domain.User{ID: "u1", IsActive: true, TeamID: 1, TeamIDs: []int64{1}}, "backend", nil)
+
+	teams := new(MockTeamRepository)
+	teams.On("GetSettings", int64(1)).Return(settings, nil)
+
+	repo := new(MockPullRequestRepository)
+	repo.On("Exists", "pr-1").Return(false, nil)
+	repo.On("GetTeamMembers", int64(1), "u1").Return([]string{"m1", "m2", "l1"}, nil)
+	repo.On("Create", mock.Anything).Return(nil)
+	repo.On("AssignReviewers", "pr-1", mock.Anything).Return(nil)
+
+	sel := services.NewReviewerSelectors(repo, services.StrategyRandom, nil)
+	return repo, teams, services.NewPullRequestService(repo, users, teams, nil, nil, sel, nil)
+}
+
+func TestCreate_LeadRuleAlwaysAssignsLead(t *testing.T) {
+	_, teams, svc := leadCreateFixture(&domain.TeamSettings{ReviewersCount: 1, MinReviewers: 1, LeadRule: services.LeadRuleAlways})
+	teams.On("GetLeadIDs", int64(1)).Return([]string{"l1"}, nil)
+
+	// единственное место занимает лид, кого бы ни выбрала стратегия
+	pr, err := svc.Create(&domain.PullRequest{ID: "pr-1", Name: "fix", AuthorID: "u1"}, "u1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"l1"}, pr.ReviewerIDs())
+}
+
+func TestCreate_LeadRuleTitleNotMatched(t *testing.T) {
+	_, teams, svc := leadCreateFixture(&domain.TeamSettings{ReviewersCount: 2, LeadRule: services.LeadRuleTitle, LeadTitlePattern: `(?i)^security`})
+
+	pr, err := svc.Create(&domain.PullRequest{ID: "pr-1", Name: "fix typo", AuthorID: "u1"}, "u1")
+	require.NoError(t, err)
+	assert.Len(t, pr.ReviewerIDs(), 2)
+	teams.AssertNotCalled(t, "GetLeadIDs", mock.Anything)
+}
+
+func TestCreate_LeadRequiredWithoutLeads(t *testing.T) {
+	_, teams, svc := leadCreateFixture(&domain.TeamSettings{ReviewersCount: 2, LeadRule: services.LeadRuleTitle, LeadTitlePattern: `(?i)^security`})
+	teams.On("GetLeadIDs", int64(1)).Return([]string{"away"}, nil)
+
+	_, err := svc.Create(&domain.PullRequest{ID: "pr-1", Name: "Security: rotate keys", AuthorID: "u1"}, "u1")
+	assert.ErrorIs(t, err, domain.ErrLeadRequired)
+}
+
+func leadReassignFixture(users *MockUserRepository) (*MockPullRequestRepository, services.PullRequestService) {
+	repo := new(MockPullRequestRepository)
+	repo.On("GetByID", "pr-1").Return(&domain.PullRequest{
+		ID: "pr-1", Name: "fix", AuthorID: "u1", TeamID: 1, TeamName: "backend", Status: domain.StatusOpen,
+		AssignedReviewers: []domain.Reviewer{{UserID: "l1"}, {UserID: "m1"}},
+	}, nil)
+
+	teams := new(MockTeamRepository)
+	teams.On("GetSettings", int64(1)).Return(&domain.TeamSettings{ReviewersCount: 2, LeadRule: services.LeadRuleAlways}, nil)
+	teams.On("GetLeadIDs", int64(1)).Return([]string{"l1", "l2"}, nil)
+
+	sel := services.NewReviewerSelectors(repo, services.StrategyRandom, nil)
+	return repo, services.NewPullRequestService(repo, users, teams, nil, nil, sel, nil)
+}
+
+func TestReassign_LeadReplacedByLead(t *testing.T) {
+	repo, svc := leadReassignFixture(new(MockUserRepository))
+	repo.On("FindReplacement", int64(1), "u1", "l1", []string{"l1", "m1"}).Return([]string{"m2", "l2"}, nil)
+	repo.On("ReplaceReviewer", "pr-1", "l1", "l2", false).Return(nil)
+
+	_, newID, err := svc.Reassign("pr-1", "l1", "", "")
+	require.NoError(t, err)
+	assert.Equal(t, "l2", newID)
+}
+
+func TestReassign_ManualLeadReplacementMustBeLead(t *testing.T) {
+	users := new(MockUserRepository)
+	users.On("GetById", "m2").Return(&domain.User{ID: "m2", IsActive: true, TeamID: 1, TeamIDs: []int64{1}}, "backend", nil)
+	users.On("IsAvailable", "m2").Return(true, nil)
+
+	repo, svc := leadReassignFixture(users)
+
+	_, _, err := svc.Reassign("pr-1", "l1", "m2", "")
+	assert.ErrorIs(t, err, domain.ErrLeadRequired)
+	repo.AssertNotCalled(t, "ReplaceReviewer", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestTeamService_UpdateSettings_InvalidLeadPattern(t *testing.T) {
+	teams := new(MockTeamRepository)
+	teams.On("Get", "backend").Return(&domain.Team{ID: 1, TeamName: "backend", Settings: domain.DefaultTeamSettings()}, nil)
+
+	svc := services.NewTeamService(teams, new(MockUserRepository), new(MockReviewReassigner))
+
+	rule, pattern := services.LeadRuleTitle, "security("
+	_, err := svc.UpdateSettings("backend", &domain.TeamSettingsUpdate{LeadRule: &rule, LeadTitlePattern: &pattern})
+	assert.ErrorIs(t, err, domain.ErrInvalidSettings)
+	teams.AssertNotCalled(t, "UpdateSettings", mock.Anything, mock.Anything)
+}
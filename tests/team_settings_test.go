@@ -0,0 +1,118 @@
+package tests
+
+import (
+	"pr-reviewer/internal/domain"
+	"pr-reviewer/internal/services"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func (m *MockTeamRepository) Exist(teamName string) (bool, error) {
+	args := m.Called(teamName)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockTeamRepository) UpdateSettings(teamID int64, settings *domain.TeamSettings) error {
+	return m.Called(teamID, settings).Error(0)
+}
+
+// createFixture — автор u1 из команды backend (id 1) с настройками settings;
+// участников команд задаёт тест через GetTeamMembers
+func createFixture(settings *domain.TeamSettings) (*MockPullRequestRepository, *MockTeamRepository, services.PullRequestService) {
+	users := new(MockUserRepository)
+	users.On("GetById", "u1").Return(&domain.User{ID: "u1", IsActive: true, TeamID: 1, TeamIDs: []int64{1}}, "backend", nil)
+
+	teams := new(MockTeamRepository)
+	teams.On("GetSettings", int64(1)).Return(settings, nil)
+
+	repo := new(MockPullRequestRepository)
+	repo.On("Exists", "pr-1").Return(false, nil)
+	repo.On("Create", mock.Anything).Return(nil)
+	repo.On("AssignReviewers", "pr-1", mock.Anything).Return(nil)
+
+	sel := services.NewReviewerSelectors(repo, services.StrategyRandom, nil)
+	return repo, teams, services.NewPullRequestService(repo, users, teams, nil, nil, sel, nil)
+}
+
+func settingsFixture() (*MockTeamRepository, services.TeamService) {
+	teams := new(MockTeamRepository)
+	teams.On("Get", "backend").Return(&domain.Team{ID: 1, TeamName: "backend", Settings: domain.DefaultTeamSettings()}, nil)
+
+	return teams, services.NewTeamService(teams, new(MockUserRepository), new(MockReviewReassigner))
+}
+
+func TestTeamService_UpdateSettings_Partial(t *testing.T) {
+	teams, svc := settingsFixture()
+	teams.On("Exist", "platform").Return(true, nil)
+	teams.On("UpdateSettings", int64(1), mock.Anything).Return(nil)
+
+	// не переданные поля сохраняют прежние значения
+	minReviewers, fallback := 1, []string{"platform"}
+	settings, err := svc.UpdateSettings("backend", &domain.TeamSettingsUpdate{MinReviewers: &minReviewers, FallbackTeams: &fallback})
+	require.NoError(t, err)
+	assert.Equal(t, domain.DefaultReviewersCount, settings.ReviewersCount)
+	assert.Equal(t, 1, settings.MinReviewers)
+	assert.Equal(t, domain.DefaultRequiredApprovals, settings.RequiredApprovals)
+	assert.Equal(t, []string{"platform"}, settings.FallbackTeams)
+	teams.AssertCalled(t, "UpdateSettings", int64(1), settings)
+}
+
+func TestTeamService_UpdateSettings_Invalid(t *testing.T) {
+	tooMany, negative := 5, -1
+	unknown, self := []string{"ghost"}, []string{"backend"}
+	strategy := "fastest"
+
+	cases := map[string]*domain.TeamSettingsUpdate{
+		"min_reviewers > reviewers_count": {MinReviewers: &tooMany},
+		"negative reviewers_count":        {ReviewersCount: &negative},
+		"negative required_approvals":     {RequiredApprovals: &negative},
+		"unknown strategy":                {Strategy: &strategy},
+		"unknown fallback team":           {FallbackTeams: &unknown},
+		"team is its own fallback":        {FallbackTeams: &self},
+	}
+
+	for name, update := range cases {
+		t.Run(name, func(t *testing.T) {
+			teams, svc := settingsFixture()
+			teams.On("Exist", "ghost").Return(false, nil)
+
+			_, err := svc.UpdateSettings("backend", update)
+			assert.ErrorIs(t, err, domain.ErrInvalidSettings)
+			teams.AssertNotCalled(t, "UpdateSettings", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestTeamService_GetSettings_UnknownTeam(t *testing.T) {
+	teams := new(MockTeamRepository)
+	teams.On("Get", "ghost").Return(nil, domain.ErrNotFound)
+
+	svc := services.NewTeamService(teams, new(MockUserRepository), new(MockReviewReassigner))
+
+	_, err := svc.GetSettings("ghost")
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+}
+
+func TestCreate_UsesTeamReviewersCount(t *testing.T) {
+	repo, _, svc := createFixture(&domain.TeamSettings{ReviewersCount: 3, MinReviewers: 1})
+	repo.On("GetTeamMembers", int64(1), "u1").Return([]string{"u2", "u3", "u4", "u5"}, nil)
+
+	pr, err := svc.Create(&domain.PullRequest{ID: "pr-1", Name: "x", AuthorID: "u1"}, "")
+	require.NoError(t, err)
+	assert.Len(t, pr.AssignedReviewers, 3)
+	assert.Empty(t, pr.Warnings)
+}
+
+func TestCreate_NotEnoughReviewers(t *testing.T) {
+	repo, teams, svc := createFixture(&domain.TeamSettings{ReviewersCount: 2, MinReviewers: 2})
+	teams.On("GetFallbackTeamIDs", int64(1)).Return(nil, nil)
+	repo.On("GetTeamMembers", int64(1), "u1").Return([]string{"u2"}, nil)
+	repo.On("CountAtCapacity", []int64{1}, []string{"u2", "u1"}).Return(0, nil)
+
+	_, err := svc.Create(&domain.PullRequest{ID: "pr-1", Name: "x", AuthorID: "u1"}, "")
+	assert.ErrorIs(t, err, domain.ErrNotEnoughReviewers)
+	repo.AssertNotCalled(t, "Create", mock.Anything)
+}
@@ -0,0 +1,213 @@
+package tests
+
+import (
+	"database/sql"
+	"errors"
+	"pr-reviewer/internal/domain"
+	"pr-reviewer/internal/repository"
+	"pr-reviewer/internal/services"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockTeamRepository struct {
+	repository.TeamRepository
+	mock.Mock
+}
+
+func (m *MockTeamRepository) Get(teamName string) (*domain.Team, error) {
+	args := m.Called(teamName)
+	team, _ := args.Get(0).(*domain.Team)
+	return team, args.Error(1)
+}
+
+func (m *MockTeamRepository) AddMember(teamID int64, user *domain.User, primary bool, events ...domain.Event) error {
+	return m.Called(teamID, user, primary, events).Error(0)
+}
+
+func (m *MockTeamRepository) RemoveMember(teamID int64, userID string, events ...domain.Event) error {
+	return m.Called(teamID, userID, events).Error(0)
+}
+
+func (m *MockTeamRepository) Delete(teamID int64) error {
+	return m.Called(teamID).Error(0)
+}
+
+func eventTypes(events []domain.Event) []string {
+	types := make([]string, 0, len(events))
+	for _, e := range events {
+		types = append(types, e.Type)
+	}
+	return types
+}
+
+func TestTeamService_AddMember_SecondTeamKeepsPrimary(t *testing.T) {
+	teams := new(MockTeamRepository)
+	teams.On("Get", "platform").Return(&domain.Team{ID: 2, TeamName: "platform"}, nil)
+
+	users := new(MockUserRepository)
+	users.On("GetById", "u1").Return(&domain.User{ID: "u1", UserName: "Alice", IsActive: true, TeamID: 1, TeamIDs: []int64{1}}, "backend", nil)
+
+	teams.On("AddMember", int64(2), mock.Anything, false, mock.MatchedBy(func(events []domain.Event) bool {
+		return len(events) == 1 && events[0].Type == domain.EventUserTeamChanged &&
+			string(events[0].Payload) == `{"joined_team":"platform"}`
+	})).Return(nil)
+
+	reassigner := new(MockReviewReassigner)
+	svc := services.NewTeamService(teams, users, reassigner)
+
+	user, err := svc.AddMember("platform", &domain.User{ID: "u1", UserName: "Alice"}, false, "lead")
+	require.NoError(t, err)
+	assert.Equal(t, "backend", user.TeamName)
+	teams.AssertExpectations(t)
+	reassigner.AssertNotCalled(t, "ReassignAll", mock.Anything, mock.Anything)
+}
+
+func TestTeamService_AddMember_NewUser(t *testing.T) {
+	teams := new(MockTeamRepository)
+	teams.On("Get", "frontend").Return(&domain.Team{ID: 2, TeamName: "frontend"}, nil)
+	teams.On("AddMember", int64(2), mock.Anything, false, mock.Anything).Return(nil)
+
+	users := new(MockUserRepository)
+	users.On("GetById", "u9").Return((*domain.User)(nil), "", sql.ErrNoRows)
+
+	svc := services.NewTeamService(teams, users, new(MockReviewReassigner))
+
+	user, err := svc.AddMember("frontend", &domain.User{ID: "u9", UserName: "Nina"}, false, "")
+	require.NoError(t, err)
+	assert.True(t, user.IsActive)
+	assert.Equal(t, "frontend", user.TeamName)
+}
+
+func TestTeamService_RemoveMember_LastTeam(t *testing.T) {
+	teams := new(MockTeamRepository)
+	teams.On("Get", "backend").Return(&domain.Team{ID: 1, TeamName: "backend"}, nil)
+	teams.On("RemoveMember", int64(1), "u1", mock.MatchedBy(func(events []domain.Event) bool {
+		return assert.ObjectsAreEqual([]string{domain.EventUserTeamChanged, domain.EventUserDeactivated}, eventTypes(events))
+	})).Return(nil)
+
+	users := new(MockUserRepository)
+	users.On("GetById", "u1").Return(&domain.User{ID: "u1", IsActive: true, TeamID: 1, TeamIDs: []int64{1}}, "backend", nil)
+
+	reassigner := new(MockReviewReassigner)
+	reassigner.On("ReassignAll", "u1", "").Return(&domain.ReassignReport{}, nil)
+
+	svc := services.NewTeamService(teams, users, reassigner)
+
+	_, err := svc.RemoveMember("backend", "u1", "")
+	require.NoError(t, err)
+	teams.AssertExpectations(t)
+}
+
+func TestTeamService_RemoveMember_KeepsOtherTeams(t *testing.T) {
+	teams := new(MockTeamRepository)
+	teams.On("Get", "backend").Return(&domain.Team{ID: 1, TeamName: "backend"}, nil)
+	teams.On("RemoveMember", int64(1), "u1", mock.MatchedBy(func(events []domain.Event) bool {
+		return assert.ObjectsAreEqual([]string{domain.EventUserTeamChanged}, eventTypes(events))
+	})).Return(nil)
+
+	users := new(MockUserRepository)
+	users.On("GetById", "u1").Return(&domain.User{ID: "u1", IsActive: true, TeamID: 1, TeamIDs: []int64{1, 2}}, "backend", nil)
+
+	// переназначаются только ревью в PR команды, из которой пользователь ушёл
+	reassigner := new(MockReviewReassigner)
+	reassigner.On("ReassignTeam", "u1", int64(1), "").Return(&domain.ReassignReport{}, nil)
+
+	svc := services.NewTeamService(teams, users, reassigner)
+
+	_, err := svc.RemoveMember("backend", "u1", "")
+	require.NoError(t, err)
+	teams.AssertExpectations(t)
+	reassigner.AssertNotCalled(t, "ReassignAll", mock.Anything, mock.Anything)
+}
+
+func TestTeamService_RemoveMember_RemovesBeforeReassign(t *testing.T) {
+	var calls []string
+
+	teams := new(MockTeamRepository)
+	teams.On("Get", "backend").Return(&domain.Team{ID: 1, TeamName: "backend"}, nil)
+	teams.On("RemoveMember", int64(1), "u1", mock.Anything).Return(nil).
+		Run(func(mock.Arguments) { calls = append(calls, "RemoveMember") })
+
+	users := new(MockUserRepository)
+	users.On("GetById", "u1").Return(&domain.User{ID: "u1", IsActive: true, TeamID: 1, TeamIDs: []int64{1, 2}}, "backend", nil)
+
+	reassigner := new(MockReviewReassigner)
+	reassigner.On("ReassignTeam", "u1", int64(1), "").Return(&domain.ReassignReport{}, nil).
+		Run(func(mock.Arguments) { calls = append(calls, "ReassignTeam") })
+
+	svc := services.NewTeamService(teams, users, reassigner)
+
+	// иначе пользователь мог бы стать заменой самому себе в этой же команде
+	_, err := svc.RemoveMember("backend", "u1", "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"RemoveMember", "ReassignTeam"}, calls)
+}
+
+func TestTeamService_RemoveMember_PartialReassignment(t *testing.T) {
+	teams := new(MockTeamRepository)
+	teams.On("Get", "backend").Return(&domain.Team{ID: 1, TeamName: "backend"}, nil)
+	teams.On("RemoveMember", int64(1), "u1", mock.Anything).Return(nil)
+
+	users := new(MockUserRepository)
+	users.On("GetById", "u1").Return(&domain.User{ID: "u1", IsActive: true, TeamID: 1, TeamIDs: []int64{1}}, "backend", nil)
+
+	partial := &domain.ReassignReport{
+		Reassigned: []domain.ReassignedReview{{PullRequestID: "pr-1", ReplacedBy: "u2"}},
+		Pending:    []string{"pr-2"},
+	}
+	reassigner := new(MockReviewReassigner)
+	reassigner.On("ReassignAll", "u1", "").Return(partial, errors.New("connection reset"))
+
+	svc := services.NewTeamService(teams, users, reassigner)
+
+	report, err := svc.RemoveMember("backend", "u1", "")
+	assert.Error(t, err)
+	assert.Equal(t, partial, report)
+	teams.AssertCalled(t, "RemoveMember", int64(1), "u1", mock.Anything)
+}
+
+func TestTeamService_RemoveMember_RepositoryError(t *testing.T) {
+	teams := new(MockTeamRepository)
+	teams.On("Get", "backend").Return(&domain.Team{ID: 1, TeamName: "backend"}, nil)
+	teams.On("RemoveMember", int64(1), "u1", mock.Anything).Return(errors.New("connection reset"))
+
+	users := new(MockUserRepository)
+	users.On("GetById", "u1").Return(&domain.User{ID: "u1", IsActive: true, TeamID: 1, TeamIDs: []int64{1}}, "backend", nil)
+
+	reassigner := new(MockReviewReassigner)
+	svc := services.NewTeamService(teams, users, reassigner)
+
+	// членство не снято — ничего не переназначаем и частичного отчёта нет
+	report, err := svc.RemoveMember("backend", "u1", "")
+	assert.Error(t, err)
+	assert.Nil(t, report)
+	reassigner.AssertNotCalled(t, "ReassignAll", mock.Anything, mock.Anything)
+}
+
+func TestTeamService_RemoveMember_OtherTeam(t *testing.T) {
+	teams := new(MockTeamRepository)
+	teams.On("Get", "backend").Return(&domain.Team{ID: 1, TeamName: "backend"}, nil)
+
+	users := new(MockUserRepository)
+	users.On("GetById", "u1").Return(&domain.User{ID: "u1", TeamID: 2, TeamIDs: []int64{2}}, "frontend", nil)
+
+	svc := services.NewTeamService(teams, users, new(MockReviewReassigner))
+
+	_, err := svc.RemoveMember("backend", "u1", "")
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+}
+
+func TestTeamService_Delete_NotEmpty(t *testing.T) {
+	teams := new(MockTeamRepository)
+	teams.On("Get", "backend").Return(&domain.Team{ID: 1, TeamName: "backend", Members: []domain.User{{ID: "u1"}}}, nil)
+
+	svc := services.NewTeamService(teams, new(MockUserRepository), new(MockReviewReassigner))
+
+	err := svc.Delete("backend")
+	assert.ErrorIs(t, err, domain.ErrTeamNotEmpty)
+	teams.AssertNotCalled(t, "Delete", mock.Anything)
+}
@@ -0,0 +1,86 @@
+package tests
+
+import (
+	"database/sql"
+	"pr-reviewer/internal/domain"
+	"pr-reviewer/internal/services"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserList_CursorContinuesAfterLastUser(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+
+	mockRepo.On("List", mock.Anything, "").Return(
+		[]domain.UserResponse{{UserID: "u1"}, {UserID: "u2"}}, "u2", nil,
+	).Once()
+	mockRepo.On("List", mock.Anything, "u2").Return(
+		[]domain.UserResponse{{UserID: "u3"}}, "", nil,
+	).Once()
+
+	svc := services.NewUserService(mockRepo, new(MockReviewReassigner))
+
+	first, err := svc.List(&domain.UserFilter{Limit: 2})
+	assert.NoError(t, err)
+	assert.Len(t, first.Users, 2)
+	assert.NotNil(t, first.NextCursor)
+
+	second, err := svc.List(&domain.UserFilter{Limit: 2, Cursor: *first.NextCursor})
+	assert.NoError(t, err)
+	assert.Equal(t, "u3", second.Users[0].UserID)
+	assert.Nil(t, second.NextCursor)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestUserList_InvalidLimit(t *testing.T) {
+	svc := services.NewUserService(new(MockUserRepository), new(MockReviewReassigner))
+
+	_, err := svc.List(&domain.UserFilter{Limit: domain.MaxPageLimit + 1})
+	assert.ErrorIs(t, err, domain.ErrInvalidFilter)
+
+	_, err = svc.List(&domain.UserFilter{Cursor: "%%%"})
+	assert.ErrorIs(t, err, domain.ErrInvalidCursor)
+}
+
+func TestUserUpdateUsername_NotFound(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	mockRepo.On("GetById", "ghost").Return((*domain.User)(nil), "", sql.ErrNoRows)
+
+	svc := services.NewUserService(mockRepo, new(MockReviewReassigner))
+
+	_, err := svc.UpdateUsername("ghost", "Bob")
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+	mockRepo.AssertNotCalled(t, "SetUsername", mock.Anything, mock.Anything)
+}
+
+func TestUserUpdateUsername_Validation(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	svc := services.NewUserService(mockRepo, new(MockReviewReassigner))
+
+	for _, name := range []string{"", "   ", strings.Repeat("я", 51)} {
+		_, err := svc.UpdateUsername("u1", name)
+		assert.ErrorIs(t, err, domain.ErrInvalidUsername, "username %q", name)
+	}
+	mockRepo.AssertNotCalled(t, "GetById", mock.Anything)
+	mockRepo.AssertNotCalled(t, "SetUsername", mock.Anything, mock.Anything)
+}
+
+func TestUserUpdateUsername_TrimsAndCountsRunes(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	mockRepo.On("GetById", "u1").Return(&domain.User{ID: "u1", UserName: "Bob", IsActive: true}, "backend", nil)
+	// 50 символов кириллицы — 100 байт, но в VARCHAR(50) помещается
+	name := strings.Repeat("я", 50)
+	mockRepo.On("SetUsername", "u1", name).Return(nil)
+
+	svc := services.NewUserService(mockRepo, new(MockReviewReassigner))
+
+	user, err := svc.UpdateUsername("u1", "  "+name+"  ")
+	require.NoError(t, err)
+	assert.Equal(t, name, user.UserName)
+	mockRepo.AssertExpectations(t)
+}
@@ -5,6 +5,7 @@ import (
 	"pr-reviewer/internal/domain"
 	"pr-reviewer/internal/services"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -19,11 +20,55 @@ func (m *MockUserRepository) GetById(userId string) (*domain.User, string, error
 	return args.Get(0).(*domain.User), args.String(1), args.Error(2)
 }
 
-func (m *MockUserRepository) SetIsActive(userId string, value bool) error {
+func (m *MockUserRepository) SetIsActive(userId string, value bool, events ...domain.Event) error {
+	args := m.Called(userId, value, events)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) SetMaxOpenReviews(userId string, value *int) error {
 	args := m.Called(userId, value)
 	return args.Error(0)
 }
 
+func (m *MockUserRepository) AddUnavailability(period *domain.Unavailability) error {
+	args := m.Called(period)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) ListUnavailability(userId string) ([]domain.Unavailability, error) {
+	args := m.Called(userId)
+	return args.Get(0).([]domain.Unavailability), args.Error(1)
+}
+
+func (m *MockUserRepository) DeleteUnavailability(id int64) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) List(filter *domain.UserFilter, afterID string) ([]domain.UserResponse, string, error) {
+	args := m.Called(filter, afterID)
+	return args.Get(0).([]domain.UserResponse), args.String(1), args.Error(2)
+}
+
+func (m *MockUserRepository) SetUsername(userId, username string) error {
+	args := m.Called(userId, username)
+	return args.Error(0)
+}
+
+type MockReviewReassigner struct {
+	mock.Mock
+}
+
+func (m *MockReviewReassigner) ReassignAll(userID, actor string) (*domain.ReassignReport, error) {
+	args := m.Called(userID, actor)
+	return args.Get(0).(*domain.ReassignReport), args.Error(1)
+}
+
+func (m *MockReviewReassigner) ReassignTeam(userID string, teamID int64, actor string) (*domain.ReassignReport, error) {
+	args := m.Called(userID, teamID, actor)
+	return args.Get(0).(*domain.ReassignReport), args.Error(1)
+}
+
 
 func TestUserService_SetIsActive_OK(t *testing.T) {
 	mockRepo := new(MockUserRepository)
@@ -34,16 +79,29 @@ func TestUserService_SetIsActive_OK(t *testing.T) {
 		nil,
 	)
 
-	mockRepo.On("SetIsActive", "u1", false).Return(nil)
+	// событие деактивации пишется в одной транзакции с флагом
+	mockRepo.On("SetIsActive", "u1", false, mock.MatchedBy(func(events []domain.Event) bool {
+		return len(events) == 1 && events[0].UserID == "u1" && events[0].Actor == "lead" &&
+			events[0].Type == domain.EventUserDeactivated
+	})).Return(nil)
+
+	report := &domain.ReassignReport{
+		Reassigned:  []domain.ReassignedReview{{PullRequestID: "pr-1", ReplacedBy: "u2"}},
+		NoCandidate: []domain.SkippedReview{{PullRequestID: "pr-2", Reason: "NO_CANDIDATE"}},
+	}
+	mockReassigner := new(MockReviewReassigner)
+	mockReassigner.On("ReassignAll", "u1", "lead").Return(report, nil)
 
-	svc := services.NewUserService(mockRepo)
+	svc := services.NewUserService(mockRepo, mockReassigner)
 
-	_, err := svc.SetIsActive("u1", false)
+	_, got, err := svc.SetIsActive("u1", false, "lead")
 	assert.NoError(t, err)
+	assert.Equal(t, report, got)
 	mockRepo.AssertExpectations(t)
+	mockReassigner.AssertExpectations(t)
 }
 
-func TestUserService_SetIsActive_AlreadyState(t *testing.T) {
+func TestUserService_SetIsActive_ActivateSkipsReassign(t *testing.T) {
 	mockRepo := new(MockUserRepository)
 
 	mockRepo.On("GetById", "u1").Return(
@@ -52,9 +110,30 @@ func TestUserService_SetIsActive_AlreadyState(t *testing.T) {
 		nil,
 	)
 
-	svc := services.NewUserService(mockRepo)
+	mockRepo.On("SetIsActive", "u1", true, mock.Anything).Return(nil)
+
+	mockReassigner := new(MockReviewReassigner)
+
+	svc := services.NewUserService(mockRepo, mockReassigner)
+
+	_, report, err := svc.SetIsActive("u1", true, "")
+	assert.NoError(t, err)
+	assert.Nil(t, report)
+	mockReassigner.AssertNotCalled(t, "ReassignAll", mock.Anything, mock.Anything)
+}
+
+func TestUserService_SetIsActive_AlreadyState(t *testing.T) {
+	mockRepo := new(MockUserRepository)
 
-	_, err := svc.SetIsActive("u1", false)
+	mockRepo.On("GetById", "u1").Return(
+		&domain.User{ID: "u1", UserName: "Alice", IsActive: true, TeamID: 1},
+		"backend",
+		nil,
+	)
+
+	svc := services.NewUserService(mockRepo, new(MockReviewReassigner))
+
+	_, _, err := svc.SetIsActive("u1", true, "")
 	assert.ErrorIs(t, err, domain.ErrAlreadyInState)
 }
 
@@ -65,9 +144,20 @@ func TestUserService_SetIsActive_NotFound(t *testing.T) {
 		(*domain.User)(nil), "", sql.ErrNoRows,
 	)
 
-	svc := services.NewUserService(mockRepo)
+	svc := services.NewUserService(mockRepo, new(MockReviewReassigner))
 
-	_, err := svc.SetIsActive("404", false)
+	_, _, err := svc.SetIsActive("404", false, "")
 
 	assert.ErrorIs(t, err, domain.ErrNotFound)
 }
+
+func TestUserService_AddUnavailability_InvalidPeriod(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	svc := services.NewUserService(mockRepo, new(MockReviewReassigner))
+
+	start := time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC)
+	err := svc.AddUnavailability(&domain.Unavailability{UserID: "u1", StartsAt: start, EndsAt: start})
+
+	assert.ErrorIs(t, err, domain.ErrInvalidPeriod)
+	mockRepo.AssertNotCalled(t, "AddUnavailability", mock.Anything)
+}
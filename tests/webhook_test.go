@@ -0,0 +1,202 @@
+package tests
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"pr-reviewer/internal/domain"
+	"pr-reviewer/internal/services"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockWebhookRepository struct {
+	mock.Mock
+
+	mu         sync.Mutex
+	deliveries []domain.WebhookDelivery // снимки после каждой попытки
+}
+
+func (m *MockWebhookRepository) Create(hook *domain.Webhook) error {
+	return m.Called(hook).Error(0)
+}
+
+func (m *MockWebhookRepository) List() ([]domain.Webhook, error) {
+	args := m.Called()
+	return args.Get(0).([]domain.Webhook), args.Error(1)
+}
+
+func (m *MockWebhookRepository) Delete(id int64) error {
+	return m.Called(id).Error(0)
+}
+
+func (m *MockWebhookRepository) CreateDelivery(d *domain.WebhookDelivery) error {
+	d.ID = 7
+	return nil
+}
+
+func (m *MockWebhookRepository) UpdateDelivery(d *domain.WebhookDelivery) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.deliveries = append(m.deliveries, *d)
+	return nil
+}
+
+func (m *MockWebhookRepository) PendingDeliveries() ([]domain.PendingDelivery, error) {
+	args := m.Called()
+	return args.Get(0).([]domain.PendingDelivery), args.Error(1)
+}
+
+func (m *MockWebhookRepository) ListDeliveries(webhookID int64, status string) ([]domain.WebhookDelivery, error) {
+	args := m.Called(webhookID, status)
+	return args.Get(0).([]domain.WebhookDelivery), args.Error(1)
+}
+
+func TestWebhookDispatcher_SignsAndRetries(t *testing.T) {
+	var calls atomic.Int32
+	var gotSignature, gotType string
+	var gotBody []byte
+
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// первая попытка падает, вторая проходит
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		gotSignature = r.Header.Get("X-Signature-256")
+		gotType = r.Header.Get("X-Event-Type")
+		gotBody, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+
+	repo := new(MockWebhookRepository)
+	repo.On("List").Return([]domain.Webhook{
+		{ID: 1, URL: receiver.URL, Secret: "s3cret", Events: []string{domain.EventPRMerged}},
+		{ID: 2, URL: receiver.URL, Secret: "other", Events: []string{domain.EventPRClosed}},
+	}, nil)
+
+	d := services.NewWebhookDispatcher(repo, receiver.Client(), 3, time.Millisecond)
+	assert.NoError(t, d.Publish(domain.Event{ID: 42, PullRequestID: "pr-1", Type: domain.EventPRMerged}))
+	d.Wait()
+
+	assert.Equal(t, int32(2), calls.Load())
+	assert.Equal(t, domain.EventPRMerged, gotType)
+	assert.Equal(t, services.SignPayload("s3cret", gotBody), gotSignature)
+
+	assert.Len(t, repo.deliveries, 2)
+	last := repo.deliveries[len(repo.deliveries)-1]
+	assert.Equal(t, domain.DeliverySuccess, last.Status)
+	assert.Equal(t, 2, last.Attempts)
+	assert.Equal(t, http.StatusNoContent, *last.ResponseCode)
+}
+
+func TestWebhookDispatcher_FailsAfterMaxAttempts(t *testing.T) {
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer receiver.Close()
+
+	repo := new(MockWebhookRepository)
+	repo.On("List").Return([]domain.Webhook{{ID: 1, URL: receiver.URL, Secret: "s3cret"}}, nil)
+
+	d := services.NewWebhookDispatcher(repo, receiver.Client(), 3, time.Millisecond)
+	assert.NoError(t, d.Publish(domain.Event{ID: 1, Type: domain.EventPRCreated}))
+	d.Wait()
+
+	last := repo.deliveries[len(repo.deliveries)-1]
+	assert.Equal(t, domain.DeliveryFailed, last.Status)
+	assert.Equal(t, 3, last.Attempts)
+	assert.Equal(t, "unexpected status 502", last.LastError)
+}
+
+func TestWebhookDispatcher_ResumesPendingDeliveries(t *testing.T) {
+	var gotSignature, gotID string
+	var gotBody []byte
+
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotSignature = r.Header.Get("X-Signature-256")
+		gotID = r.Header.Get("X-Delivery-Id")
+		gotBody, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer receiver.Close()
+
+	event := domain.Event{ID: 42, PullRequestID: "pr-1", Actor: "u1", Type: domain.EventPRMerged, Payload: []byte(`{"force":false}`)}
+
+	// до рестарта доставка успела сделать две неудачные попытки
+	repo := new(MockWebhookRepository)
+	repo.On("PendingDeliveries").Return([]domain.PendingDelivery{{
+		Delivery: domain.WebhookDelivery{ID: 9, WebhookID: 1, EventID: 42, EventType: domain.EventPRMerged, Status: domain.DeliveryPending, Attempts: 2},
+		Webhook:  domain.Webhook{ID: 1, URL: receiver.URL, Secret: "s3cret"},
+		Event:    event,
+	}}, nil)
+
+	d := services.NewWebhookDispatcher(repo, receiver.Client(), 5, time.Millisecond)
+	assert.NoError(t, d.Resume())
+	d.Wait()
+
+	assert.Equal(t, "9", gotID)
+	assert.Equal(t, services.SignPayload("s3cret", gotBody), gotSignature)
+	assert.JSONEq(t, `{"event_id":42,"pull_request_id":"pr-1","actor":"u1","type":"PR_MERGED","payload":{"force":false},"created_at":""}`, string(gotBody))
+
+	assert.Len(t, repo.deliveries, 1)
+	assert.Equal(t, domain.DeliverySuccess, repo.deliveries[0].Status)
+	assert.Equal(t, 3, repo.deliveries[0].Attempts)
+}
+
+func TestWebhookDispatcher_ResumeFailsExhaustedDeliveries(t *testing.T) {
+	repo := new(MockWebhookRepository)
+	repo.On("PendingDeliveries").Return([]domain.PendingDelivery{{
+		Delivery: domain.WebhookDelivery{ID: 9, WebhookID: 1, EventID: 1, Status: domain.DeliveryPending, Attempts: 3},
+		Webhook:  domain.Webhook{ID: 1, URL: "http://127.0.0.1:1", Secret: "s3cret"},
+	}}, nil)
+
+	d := services.NewWebhookDispatcher(repo, http.DefaultClient, 3, time.Millisecond)
+	assert.NoError(t, d.Resume())
+	d.Wait()
+
+	assert.Len(t, repo.deliveries, 1)
+	assert.Equal(t, domain.DeliveryFailed, repo.deliveries[0].Status)
+}
+
+func TestWebhookDispatcher_ShutdownLeavesDeliveryPending(t *testing.T) {
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer receiver.Close()
+
+	repo := new(MockWebhookRepository)
+	repo.On("List").Return([]domain.Webhook{{ID: 1, URL: receiver.URL, Secret: "s3cret"}}, nil)
+
+	// повтор через час: Shutdown не должен его ждать
+	d := services.NewWebhookDispatcher(repo, receiver.Client(), 3, time.Hour)
+	assert.NoError(t, d.Publish(domain.Event{ID: 1, Type: domain.EventPRCreated}))
+
+	assert.Eventually(t, func() bool {
+		repo.mu.Lock()
+		defer repo.mu.Unlock()
+		return len(repo.deliveries) == 1
+	}, time.Second, time.Millisecond)
+
+	done := make(chan struct{})
+	go func() {
+		d.Shutdown()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("shutdown waits for retry backoff")
+	}
+
+	assert.Len(t, repo.deliveries, 1)
+	assert.Equal(t, domain.DeliveryPending, repo.deliveries[0].Status)
+	assert.Equal(t, 1, repo.deliveries[0].Attempts)
+}